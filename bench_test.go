@@ -0,0 +1,130 @@
+package errors_test
+
+import (
+	stderrors "errors"
+	"fmt"
+	"testing"
+
+	pkgerrors "github.com/pkg/errors"
+
+	"github.com/ihleven/errors"
+)
+
+var sink error
+
+func BenchmarkNew(b *testing.B) {
+	b.Run("errors", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			sink = errors.New("boom")
+		}
+	})
+	b.Run("errors/format", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			sink = errors.New("boom %d", i)
+		}
+	})
+	b.Run("errors/StackNone", func(b *testing.B) {
+		errors.SetStackMode(errors.StackNone)
+		defer errors.SetStackMode(errors.StackFull)
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			sink = errors.New("boom")
+		}
+	})
+	b.Run("errors/GoRunning", func(b *testing.B) {
+		// a goroutine launched with Go is alive while errors are created elsewhere
+		done := make(chan struct{})
+		defer close(done)
+		errors.Go(func() { <-done })
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			sink = errors.New("boom")
+		}
+	})
+	b.Run("errors/InGo", func(b *testing.B) {
+		// errors created by a goroutine launched with Go
+		done := make(chan struct{})
+		b.ReportAllocs()
+		errors.Go(func() {
+			defer close(done)
+			for i := 0; i < b.N; i++ {
+				sink = errors.New("boom")
+			}
+		})
+		<-done
+	})
+	b.Run("std", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			sink = stderrors.New("boom")
+		}
+	})
+	b.Run("std/format", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			sink = fmt.Errorf("boom %d", i)
+		}
+	})
+	b.Run("pkg", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			sink = pkgerrors.New("boom")
+		}
+	})
+	b.Run("pkg/format", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			sink = pkgerrors.Errorf("boom %d", i)
+		}
+	})
+}
+
+func BenchmarkNewWithCode(b *testing.B) {
+	b.Run("errors", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			sink = errors.NewWithCode(errors.NotFound, "boom")
+		}
+	})
+	b.Run("errors/format", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			sink = errors.NewWithCode(errors.NotFound, "boom %d", i)
+		}
+	})
+}
+
+func BenchmarkWrap(b *testing.B) {
+	errs := map[string]error{
+		"own":     errors.New("boom"),
+		"foreign": stderrors.New("boom"),
+	}
+	for name, err := range errs {
+		b.Run("errors/"+name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				sink = errors.Wrap(err, "wrapping")
+			}
+		})
+		b.Run("errors/"+name+"/format", func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				sink = errors.Wrap(err, "wrapping %d", i)
+			}
+		})
+		b.Run("std/"+name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				sink = fmt.Errorf("wrapping: %w", err)
+			}
+		})
+		b.Run("pkg/"+name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				sink = pkgerrors.Wrap(err, "wrapping")
+			}
+		})
+	}
+}
@@ -0,0 +1,65 @@
+// Package buildid reads the Go build ID of ELF executables. Importing it records the
+// build ID of the running executable in the BuildInfo of package errors, encoded along
+// with stack traces of the stack encoding EncodePCs:
+//
+//	import _ "github.com/ihleven/errors/buildid"
+//
+// The package is separate to spare programs not encoding program counters the size of
+// package debug/elf. Without it, BuildInfo identifies the executable by module, version
+// and revision only, as it does for executables in other formats like Mach-O on macOS
+// or PE on Windows: only ELF is supported.
+package buildid
+
+import (
+	"bytes"
+	"debug/elf"
+	"os"
+
+	"github.com/ihleven/errors"
+)
+
+func init() {
+	if exe, err := os.Executable(); err == nil {
+		if id, err := Read(exe); err == nil {
+			errors.SetBuildID(id)
+		}
+	}
+}
+
+// Read returns the Go build ID of the ELF executable name.
+func Read(name string) (string, error) {
+
+	f, err := elf.Open(name)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	id, err := ReadELF(f)
+	if err != nil {
+		return "", errors.Wrap(err, "%s", name)
+	}
+	return id, nil
+}
+
+// ReadELF returns the Go build ID of the ELF file f.
+func ReadELF(f *elf.File) (string, error) {
+
+	section := f.Section(".note.go.buildid")
+	if section == nil {
+		return "", errors.New("no Go build ID")
+	}
+	note, err := section.Data()
+	if err != nil {
+		return "", errors.Wrap(err, "reading Go build ID")
+	}
+	// the note is the 12 byte header, the name "Go\x00\x00" and the ID
+	if len(note) < 16 || !bytes.Equal(note[12:16], []byte("Go\x00\x00")) {
+		return "", errors.New("malformed Go build ID")
+	}
+	size := f.ByteOrder.Uint32(note[4:8])
+	if int(size) > len(note)-16 {
+		return "", errors.New("malformed Go build ID")
+	}
+	return string(note[16 : 16+size]), nil
+}
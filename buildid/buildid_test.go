@@ -0,0 +1,64 @@
+package buildid_test
+
+import (
+	"debug/elf"
+	stderrors "errors"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/ihleven/errors"
+	"github.com/ihleven/errors/buildid"
+)
+
+func TestRead(t *testing.T) {
+
+	exe := requireELF(t)
+	id, err := buildid.Read(exe)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id == "" || errors.Build().ID != id {
+		t.Errorf("Build().ID = %q, want the build ID %q read on import", errors.Build().ID, id)
+	}
+
+	if _, err := buildid.Read("buildid.go"); err == nil {
+		t.Errorf("reading the build ID of a source file succeeded")
+	}
+}
+
+// TestDependencies checks package errors does not link the ELF reader itself.
+func TestDependencies(t *testing.T) {
+
+	out, err := exec.Command("go", "list", "-deps", "github.com/ihleven/errors").Output()
+	if err != nil {
+		t.Skipf("listing dependencies: %v", err)
+	}
+	for _, pkg := range strings.Fields(string(out)) {
+		if pkg == "debug/elf" {
+			t.Errorf("package errors depends on %s", pkg)
+		}
+	}
+}
+
+// requireELF returns the path of the test binary and skips the test unless it is an
+// ELF executable, the only format supported.
+func requireELF(t *testing.T) string {
+	t.Helper()
+
+	exe, err := os.Executable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := elf.Open(exe)
+	var format *elf.FormatError
+	if stderrors.As(err, &format) {
+		t.Skipf("%s is no ELF executable", exe)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	return exe
+}
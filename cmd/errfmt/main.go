@@ -0,0 +1,152 @@
+// Command errfmt pretty-prints errors of package github.com/ihleven/errors and Go tracebacks.
+//
+// It reads errors printed with %+v, the JSON encoding of their layers or the output of
+// a panic or goroutine dump from the files given, or from standard input, and renders
+// them uniformly, each headed by a fingerprint identifying its code path:
+//
+//	errfmt [flags] [file ...]
+//
+// The flags are:
+//
+//	-binary file
+//		resolve program counters of JSON encoded with errors.EncodePCs against the executable,
+//		which has to be an ELF file
+//	-color auto|always|never
+//		color the output, by default if standard output is a terminal
+//	-group
+//		print errors sharing a fingerprint once, along with the number of occurrences
+//	-nostd
+//		hide frames of the standard library and the runtime
+//	-only prefix,...
+//		hide frames of packages other than those with the given import path prefixes
+//	-trim prefix,...
+//		remove the given prefixes from file paths
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/ihleven/errors/symbolize"
+	"github.com/ihleven/errors/traceback"
+)
+
+func main() {
+	if err := run(os.Args[1:], os.Stdin, os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, "errfmt:", err)
+		os.Exit(1)
+	}
+}
+
+// run runs errfmt with the command line arguments args.
+func run(args []string, stdin io.Reader, stdout io.Writer) error {
+
+	flags := flag.NewFlagSet("errfmt", flag.ContinueOnError)
+	color := flags.String("color", "auto", "color the output: auto, always or never")
+	grouped := flags.Bool("group", false, "print errors sharing a fingerprint once")
+	nostd := flags.Bool("nostd", false, "hide frames of the standard library and the runtime")
+	only := flags.String("only", "", "hide frames of packages other than those with the given comma separated prefixes")
+	trim := flags.String("trim", "", "remove the given comma separated prefixes from file paths")
+	binary := flags.String("binary", "", "ELF executable to resolve program counters against")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	var bin *symbolize.Binary
+	if *binary != "" {
+		var err error
+		if bin, err = symbolize.Open(*binary); err != nil {
+			return err
+		}
+	}
+
+	r := &renderer{w: stdout, trim: split(*trim)}
+	switch *color {
+	case "always":
+		r.color = true
+	case "never":
+	case "auto":
+		r.color = isTerminal(stdout)
+	default:
+		return fmt.Errorf("invalid -color %q", *color)
+	}
+	if prefixes := split(*only); *nostd || len(prefixes) > 0 {
+		r.keep = func(f traceback.Frame) bool {
+			if *nostd && isStd(f) {
+				return false
+			}
+			if len(prefixes) == 0 {
+				return true
+			}
+			for _, prefix := range prefixes {
+				if strings.HasPrefix(f.Function, prefix) {
+					return true
+				}
+			}
+			return false
+		}
+	}
+
+	var reports []*report
+	read := func(name string, in io.Reader) error {
+		input, err := io.ReadAll(in)
+		if err != nil {
+			return err
+		}
+		reps, err := parse(input, bin)
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		reports = append(reports, reps...)
+		return nil
+	}
+
+	if flags.NArg() == 0 {
+		if err := read("stdin", stdin); err != nil {
+			return err
+		}
+	}
+	for _, name := range flags.Args() {
+		f, err := os.Open(name)
+		if err != nil {
+			return err
+		}
+		err = read(name, f)
+		f.Close()
+		if err != nil {
+			return err
+		}
+	}
+
+	if *grouped {
+		reports = group(reports)
+	}
+	for _, rep := range reports {
+		r.report(rep)
+	}
+	return nil
+}
+
+// split splits a comma separated list, ignoring empty elements.
+func split(list string) []string {
+	var elems []string
+	for _, elem := range strings.Split(list, ",") {
+		if elem = strings.TrimSpace(elem); elem != "" {
+			elems = append(elems, elem)
+		}
+	}
+	return elems
+}
+
+// isTerminal reports whether w is a terminal.
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
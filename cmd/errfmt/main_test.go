@@ -0,0 +1,120 @@
+package main
+
+import (
+	"bytes"
+	"debug/elf"
+	"encoding/json"
+	stderrors "errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ihleven/errors"
+)
+
+var update = flag.Bool("update", false, "update the golden files")
+
+func TestGolden(t *testing.T) {
+
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{"text", []string{"testdata/errors.txt"}},
+		{"text_grouped", []string{"-group", "-nostd", "-trim", "/home/dev", "testdata/errors.txt"}},
+		{"json", []string{"testdata/errors.json"}},
+		{"json_grouped", []string{"-group", "-only", "example.com/shop", "testdata/errors.json"}},
+		{"panic", []string{"-nostd", "testdata/panic.txt"}},
+		{"color", []string{"-color", "always", "-trim", "/home/dev/", "testdata/panic.txt"}},
+		{"files", []string{"-group", "-nostd", "testdata/errors.txt", "testdata/errors.json"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			var out bytes.Buffer
+			if err := run(tt.args, nil, &out); err != nil {
+				t.Fatal(err)
+			}
+
+			golden := filepath.Join("testdata", tt.name+".golden")
+			if *update {
+				if err := os.WriteFile(golden, out.Bytes(), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := out.String(); got != string(want) {
+				t.Errorf("errfmt %s =\n%s\nwant\n%s", strings.Join(tt.args, " "), got, want)
+			}
+		})
+	}
+}
+
+func TestStdin(t *testing.T) {
+
+	input, err := os.ReadFile("testdata/errors.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	if err := run(nil, bytes.NewReader(input), &out); err != nil {
+		t.Fatal(err)
+	}
+	want, _ := os.ReadFile("testdata/text.golden")
+	if out.String() != string(want) {
+		t.Errorf("reading stdin =\n%s\nwant\n%s", out.String(), want)
+	}
+}
+
+func TestBinary(t *testing.T) {
+
+	if errors.GetStackTrace(errors.New("probe")) == nil {
+		t.Skip("stack traces disabled by the build tag errors_nostack")
+	}
+	errors.SetStackEncoding(errors.EncodePCs)
+	input, err := json.Marshal(errors.Layers(errors.Wrap(errors.New("raw"), "wrapped")))
+	errors.SetStackEncoding(errors.EncodeSymbols)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := run(nil, bytes.NewReader(input), &bytes.Buffer{}); err == nil {
+		t.Errorf("reading program counters without -binary succeeded")
+	}
+
+	exe := requireELF(t)
+	var out bytes.Buffer
+	if err := run([]string{"-binary", exe}, bytes.NewReader(input), &out); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "caused by: raw\n    github.com/ihleven/errors/cmd/errfmt.TestBinary\n") {
+		t.Errorf("output lacks the symbolized stack:\n%s", out.String())
+	}
+}
+
+// requireELF returns the path of the test binary and skips the test unless it is an
+// ELF executable, the only format supported.
+func requireELF(t *testing.T) string {
+	t.Helper()
+
+	exe, err := os.Executable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := elf.Open(exe)
+	var format *elf.FormatError
+	if stderrors.As(err, &format) {
+		t.Skipf("%s is no ELF executable", exe)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	return exe
+}
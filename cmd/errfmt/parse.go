@@ -0,0 +1,257 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/ihleven/errors"
+	"github.com/ihleven/errors/symbolize"
+	"github.com/ihleven/errors/traceback"
+)
+
+// report is an error or a goroutine read from the input.
+type report struct {
+	layers []layer
+	count  int // number of identical reports grouped into this one
+}
+
+// layer is a layer of an error chain, or the stack of a goroutine.
+type layer struct {
+	message   string
+	code      int               // code attached to the layer, 0 if none
+	at        *traceback.Frame  // location of a layer without stack
+	stack     []traceback.Frame // stack recorded by the layer, innermost first
+	notes     []string          // markers following the stack, e.g. "... 3 more frames"
+	createdBy [][]traceback.Frame
+}
+
+// fingerprint identifies the code path of the report, ignoring messages and lines.
+func (r *report) fingerprint() string {
+	var g traceback.Goroutine
+	for _, l := range r.layers {
+		if l.at != nil {
+			g.Frames = append(g.Frames, *l.at)
+		}
+		g.Frames = append(g.Frames, l.stack...)
+	}
+	return g.Fingerprint()
+}
+
+// parse reads the reports of input, which holds either the JSON encoding of the layers
+// of errors, a Go traceback or the %+v text format of errors. Program counters encoded
+// with errors.EncodePCs are resolved against bin.
+func parse(input []byte, bin *symbolize.Binary) ([]*report, error) {
+
+	trimmed := bytes.TrimSpace(input)
+	switch {
+	case len(trimmed) == 0:
+		return nil, nil
+	case trimmed[0] == '[' || trimmed[0] == '{':
+		return parseJSON(trimmed, bin)
+	case tracebackRe.Match(input):
+		return parseTraceback(input)
+	}
+	return parseText(input), nil
+}
+
+var tracebackRe = regexp.MustCompile(`(?m)^goroutine \d+ .*\[.*\]:$`)
+
+// jsonLayer is the JSON encoding of errors.Layer.
+type jsonLayer struct {
+	Message   string     `json:"message"`
+	Function  string     `json:"function"`
+	File      string     `json:"file"`
+	Line      int        `json:"line"`
+	Code      int        `json:"code"`
+	Stack     []string   `json:"stack"`
+	CreatedBy [][]string `json:"created_by"`
+
+	Build *errors.BuildInfo `json:"build"`
+}
+
+// parseJSON reads a stream of JSON values, each either a list of layers making up an
+// error, as returned by errors.Layers, or a single layer.
+func parseJSON(input []byte, bin *symbolize.Binary) ([]*report, error) {
+
+	var reports []*report
+
+	dec := json.NewDecoder(bytes.NewReader(input))
+	for dec.More() {
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return nil, fmt.Errorf("reading JSON: %w", err)
+		}
+		var layers []jsonLayer
+		if bytes.HasPrefix(raw, []byte("{")) {
+			layers = make([]jsonLayer, 1)
+			if err := json.Unmarshal(raw, &layers[0]); err != nil {
+				return nil, fmt.Errorf("reading JSON: %w", err)
+			}
+		} else if err := json.Unmarshal(raw, &layers); err != nil {
+			return nil, fmt.Errorf("reading JSON: %w", err)
+		}
+
+		r := &report{count: 1}
+		for _, jl := range layers {
+			l := layer{message: jl.Message, code: jl.Code}
+			parseFrameText := parseFrameText
+			if jl.Build != nil {
+				if bin == nil {
+					return nil, fmt.Errorf("stack of %q holds program counters, symbolize them with -binary", jl.Message)
+				}
+				parseFrameText = func(text string) (traceback.Frame, bool) {
+					return symbolizeFrame(bin, *jl.Build, text)
+				}
+			}
+			for _, text := range jl.Stack {
+				if f, ok := parseFrameText(text); ok {
+					l.stack = append(l.stack, f)
+				} else {
+					l.notes = append(l.notes, text)
+				}
+			}
+			for _, texts := range jl.CreatedBy {
+				var stack []traceback.Frame
+				for _, text := range texts {
+					if f, ok := parseFrameText(text); ok {
+						stack = append(stack, f)
+					}
+				}
+				l.createdBy = append(l.createdBy, stack)
+			}
+			if len(l.stack) == 0 && jl.File != "" {
+				l.at = &traceback.Frame{Function: jl.Function, File: jl.File, Line: jl.Line}
+			}
+			r.layers = append(r.layers, l)
+		}
+		reports = append(reports, r)
+	}
+	return reports, nil
+}
+
+// parseFrameText parses a frame as encoded by errors.Frame.MarshalText,
+// "function file:line".
+func parseFrameText(text string) (traceback.Frame, bool) {
+
+	i := strings.Index(text, " ")
+	j := strings.LastIndex(text, ":")
+	if i < 0 || j < i {
+		return traceback.Frame{}, false
+	}
+	line, err := strconv.Atoi(text[j+1:])
+	if err != nil {
+		return traceback.Frame{}, false
+	}
+	return traceback.Frame{Function: text[:i], File: text[i+1 : j], Line: line}, true
+}
+
+// symbolizeFrame resolves a program counter encoded with errors.EncodePCs.
+func symbolizeFrame(bin *symbolize.Binary, build errors.BuildInfo, text string) (traceback.Frame, bool) {
+
+	pc, ok := symbolize.ParsePC(text)
+	if !ok {
+		return traceback.Frame{}, false
+	}
+	frames, err := bin.Symbolize(build, []uintptr{pc})
+	if err != nil {
+		return traceback.Frame{Function: text + " (" + err.Error() + ")"}, true
+	}
+	f := frames[0]
+	return traceback.Frame{Function: f.Function, File: f.File, Line: f.Line}, true
+}
+
+// parseTraceback reads a Go traceback, each goroutine making up a report.
+// The panic message, if any, is the message of the first goroutine.
+func parseTraceback(input []byte) ([]*report, error) {
+
+	dump, err := traceback.Parse(bytes.NewReader(input))
+	if err != nil {
+		return nil, err
+	}
+
+	var reports []*report
+	for i, g := range dump.Goroutines {
+		l := layer{message: fmt.Sprint(g), stack: g.Frames}
+		if i == 0 && dump.Panic != "" {
+			l.message = "panic: " + dump.Panic
+		}
+		if g.Elided {
+			l.notes = append(l.notes, "...additional frames elided...")
+		}
+		if g.CreatedBy != nil {
+			l.createdBy = [][]traceback.Frame{{*g.CreatedBy}}
+		}
+		reports = append(reports, &report{layers: []layer{l}, count: 1})
+	}
+	return reports, nil
+}
+
+var (
+	// 	--- at file.go:12 (function)
+	atRe = regexp.MustCompile(`^\t--- at (.+):(\d+) \((.*)\)$`)
+	// 	/path/to/file.go:12
+	fileLineRe = regexp.MustCompile(`^\t(.+):(\d+)$`)
+	// ... 3 more frames
+	markerRe = regexp.MustCompile(`^\.\.\. \d+ (more|common) frames`)
+)
+
+// parseText reads errors printed with %+v. Errors are separated by blank lines.
+func parseText(input []byte) []*report {
+
+	lines := strings.Split(strings.ReplaceAll(string(input), "\r\n", "\n"), "\n")
+
+	var reports []*report
+	var r *report
+	var l *layer
+	var stack *[]traceback.Frame // stack frames are added to
+	blank := true
+
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+
+		switch {
+		case strings.TrimSpace(line) == "":
+			blank = true
+			continue
+
+		case r != nil && strings.HasPrefix(line, "Caused by: "):
+			r.layers = append(r.layers, layer{message: strings.TrimPrefix(line, "Caused by: ")})
+			l = &r.layers[len(r.layers)-1]
+			stack = &l.stack
+
+		case l != nil && atRe.MatchString(line):
+			m := atRe.FindStringSubmatch(line)
+			n, _ := strconv.Atoi(m[2])
+			l.at = &traceback.Frame{Function: m[3], File: m[1], Line: n}
+
+		case l != nil && i+1 < len(lines) && !strings.HasPrefix(line, "\t") && fileLineRe.MatchString(lines[i+1]):
+			m := fileLineRe.FindStringSubmatch(lines[i+1])
+			n, _ := strconv.Atoi(m[2])
+			*stack = append(*stack, traceback.Frame{Function: line, File: m[1], Line: n})
+			i++
+
+		case l != nil && markerRe.MatchString(line):
+			l.notes = append(l.notes, line)
+
+		case l != nil && line == "created by":
+			l.createdBy = append(l.createdBy, nil)
+			stack = &l.createdBy[len(l.createdBy)-1]
+
+		case l != nil && !blank && len(l.stack) == 0 && l.at == nil:
+			// continuation of a message spanning lines
+			l.message += "\n" + line
+
+		default:
+			r = &report{layers: []layer{{message: line}}, count: 1}
+			reports = append(reports, r)
+			l = &r.layers[0]
+			stack = &l.stack
+		}
+		blank = false
+	}
+	return reports
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/ihleven/errors/traceback"
+)
+
+// ANSI escape sequences used with colors enabled.
+const (
+	colorReset    = "\x1b[0m"
+	colorMessage  = "\x1b[1;31m"
+	colorFunction = "\x1b[36m"
+	colorFile     = "\x1b[2m"
+	colorHeader   = "\x1b[1m"
+)
+
+// renderer writes reports in a uniform, human readable format.
+type renderer struct {
+	w       io.Writer
+	color   bool
+	trim    []string                     // path prefixes removed from file names
+	keep    func(f traceback.Frame) bool // frames shown, nil for all
+	printed bool                         // a report was written already
+}
+
+func (r *renderer) paint(color, s string) string {
+	if !r.color {
+		return s
+	}
+	return color + s + colorReset
+}
+
+func (r *renderer) file(file string) string {
+	for _, prefix := range r.trim {
+		if strings.HasPrefix(file, prefix) {
+			return strings.TrimPrefix(file[len(prefix):], "/")
+		}
+	}
+	return file
+}
+
+// report writes rep, headed by its fingerprint and the number of occurrences.
+func (r *renderer) report(rep *report) {
+
+	if r.printed {
+		fmt.Fprintln(r.w)
+	}
+	r.printed = true
+
+	header := "error " + rep.fingerprint()
+	if rep.count > 1 {
+		header += fmt.Sprintf(" (%d occurrences)", rep.count)
+	}
+	fmt.Fprintln(r.w, r.paint(colorHeader, header))
+
+	for i, l := range rep.layers {
+		message := l.message
+		if l.code != 0 {
+			message += fmt.Sprintf(" [code %d]", l.code)
+		}
+		if i > 0 {
+			message = "caused by: " + message
+		}
+		fmt.Fprintln(r.w, r.paint(colorMessage, message))
+
+		if l.at != nil {
+			fmt.Fprintf(r.w, "    at %s (%s:%d)\n", r.paint(colorFunction, l.at.Function), r.paint(colorFile, r.file(l.at.File)), l.at.Line)
+		}
+		r.stack(l.stack, "    ")
+		for _, note := range l.notes {
+			fmt.Fprintf(r.w, "    %s\n", note)
+		}
+		for _, stack := range l.createdBy {
+			fmt.Fprintln(r.w, "  created by")
+			r.stack(stack, "    ")
+		}
+	}
+}
+
+// stack writes the frames of stack kept by the filter, collapsing the others.
+func (r *renderer) stack(stack []traceback.Frame, indent string) {
+
+	hidden := 0
+	flush := func() {
+		if hidden > 0 {
+			if hidden == 1 {
+				fmt.Fprintf(r.w, "%s... 1 frame hidden\n", indent)
+			} else {
+				fmt.Fprintf(r.w, "%s... %d frames hidden\n", indent, hidden)
+			}
+			hidden = 0
+		}
+	}
+	for _, f := range stack {
+		if r.keep != nil && !r.keep(f) {
+			hidden++
+			continue
+		}
+		flush()
+		fmt.Fprintf(r.w, "%s%s\n%s    %s:%d\n", indent, r.paint(colorFunction, f.Function), indent, r.paint(colorFile, r.file(f.File)), f.Line)
+	}
+	flush()
+}
+
+// isStd reports whether f belongs to the standard library or the runtime,
+// judged by the first element of its package path lacking a dot.
+func isStd(f traceback.Frame) bool {
+	pkg := f.Package()
+	if pkg == "main" {
+		return false
+	}
+	first := pkg
+	if i := strings.Index(pkg, "/"); i >= 0 {
+		first = pkg[:i]
+	}
+	return !strings.Contains(first, ".")
+}
+
+// group merges reports with the same fingerprint into the first of them, keeping the order.
+func group(reports []*report) []*report {
+
+	var grouped []*report
+	seen := make(map[string]*report)
+	for _, rep := range reports {
+		fp := rep.fingerprint()
+		if first, ok := seen[fp]; ok {
+			first.count += rep.count
+			continue
+		}
+		seen[fp] = rep
+		grouped = append(grouped, rep)
+	}
+	return grouped
+}
@@ -1,7 +1,12 @@
 package errors
 
 import (
+	"context"
+	"fmt"
+	"log/slog"
 	"math"
+	"strconv"
+	"sync"
 )
 
 // ErrorCode is a code that can be attached to an error and is passed up the stack.
@@ -17,39 +22,151 @@ const BadRequest ErrorCode = 400
 // NotFound indicates unavailable resources
 const NotFound ErrorCode = 404
 
-type coder interface {
+// Canceled is the code of context.Canceled, reported by Code for chains containing it.
+const Canceled ErrorCode = 499
+
+// DeadlineExceeded is the code of context.DeadlineExceeded, reported by Code for chains containing it.
+const DeadlineExceeded ErrorCode = 504
+
+// Error makes an ErrorCode usable as the target of Is, so that
+//
+//	errors.Is(err, errors.NotFound)
+//
+// reports whether any error in err's chain carries the code NotFound. An ErrorCode
+// returned or wrapped as an error carries its own code, see Code.
+func (c ErrorCode) Error() string {
+	return "error code " + strconv.Itoa(int(c))
+}
+
+// Code returns c, so that an ErrorCode in an error chain is found by Code and HasCode.
+func (c ErrorCode) Code() int { return int(c) }
+
+// Format formats c as the integer it is, like fmt did before ErrorCode implemented error,
+// so that fmt.Sprint(errors.NotFound) is "404". Only Error and the messages of chains
+// containing an ErrorCode read "error code 404".
+func (c ErrorCode) Format(s fmt.State, verb rune) {
+	if verb == 's' {
+		// the number rather than the complaint fmt prints for integers
+		verb = 'd'
+	}
+	fmt.Fprintf(s, fmt.FormatString(s, verb), int(c))
+}
+
+// LogValue logs c as the integer it is, rather than as an error.
+func (c ErrorCode) LogValue() slog.Value { return slog.IntValue(int(c)) }
+
+// MarshalJSON encodes c as a JSON number, rather than as an error.
+func (c ErrorCode) MarshalJSON() ([]byte, error) { return strconv.AppendInt(nil, int64(c), 10), nil }
+
+// MarshalText encodes c as the decimal integer it is, rather than as an error.
+func (c ErrorCode) MarshalText() ([]byte, error) { return strconv.AppendInt(nil, int64(c), 10), nil }
+
+// CodeInfo holds the defaults of a registered ErrorCode.
+type CodeInfo struct {
+	// Name is a short human readable name of the code, e.g. "NotFound".
+	Name string
+	// Category groups related codes, e.g. "client" or "dependency".
+	Category string
+	// Severity is the severity of errors with the code, unless set explicitly with WithSeverity.
+	Severity Severity
+	// Retry tells whether errors with the code are worth retrying, unless marked explicitly
+	// with Temporary or Permanent.
+	Retry Retryability
+}
+
+var registry = struct {
+	sync.RWMutex
+	codes map[ErrorCode]CodeInfo
+}{
+	codes: map[ErrorCode]CodeInfo{
+		BadRequest:       {Name: "BadRequest", Category: "client", Severity: SeverityInfo, Retry: RetryPermanent},
+		NotFound:         {Name: "NotFound", Category: "client", Severity: SeverityDebug, Retry: RetryPermanent},
+		Canceled:         {Name: "Canceled", Category: "context", Severity: SeverityInfo, Retry: RetryPermanent},
+		DeadlineExceeded: {Name: "DeadlineExceeded", Category: "context", Severity: SeverityWarn, Retry: RetryTemporary},
+	},
+}
+
+// RegisterCode registers the defaults of code. Registering a code again replaces its defaults.
+// Codes are typically registered in init functions.
+func RegisterCode(code ErrorCode, info CodeInfo) {
+	registry.Lock()
+	defer registry.Unlock()
+	registry.codes[code] = info
+}
+
+// LookupCode returns the defaults registered for code.
+func LookupCode(code ErrorCode) (CodeInfo, bool) {
+	registry.RLock()
+	defer registry.RUnlock()
+	info, ok := registry.codes[code]
+	return info, ok
+}
+
+// Coder is implemented by errors carrying a code, like the errors created by NewWithCode
+// or with the option WithCode. Code returns int(NoCode) if the error carries no code.
+// Errors of other packages implementing Coder are recognized by Code, HasCode and Is.
+type Coder interface {
 	Code() int
 }
 
+// codeOf returns the code attached to err itself, not looking at its causes.
+// The errors of package context are assigned the codes Canceled and DeadlineExceeded.
+func codeOf(err error) ErrorCode {
+	switch err {
+	case context.Canceled:
+		return Canceled
+	case context.DeadlineExceeded:
+		return DeadlineExceeded
+	}
+	if errWithCode, ok := err.(Coder); ok {
+		return ErrorCode(errWithCode.Code())
+	}
+	return NoCode
+}
+
 // GetCauseCode returns the attached code of the original causer of the error cascade.
 func GetCauseCode(err error) int {
 
 	cause := Cause(err)
 
-	if errWithCode, ok := cause.(coder); ok {
+	if errWithCode, ok := cause.(Coder); ok {
 		return errWithCode.Code()
 	}
 
 	return int(NoCode)
 }
 
-// Code traverses down the error cascade and return the first error with attached code it finds.
+// Code traverses down the error cascade and return the first code other than NoCode it finds.
+// The cascade is traversed as by Walk, so codes behind fmt.Errorf("%w") or other foreign
+// wrappers are found as well. context.Canceled and context.DeadlineExceeded are reported
+// as Canceled and DeadlineExceeded.
 func Code(err error) int {
 
-	type causer interface {
-		Cause() error
-	}
+	code := NoCode
 
-	for err != nil {
-		errWithCode, ok := err.(coder)
-		if ok {
-			return int(errWithCode.Code())
-		}
-		cause, ok := err.(causer)
-		if !ok {
-			break
+	Walk(err, func(e error) bool {
+		code = codeOf(e)
+		return code == NoCode
+	})
+	return int(code)
+}
+
+// HasCode reports whether any error in err's chain carries one of the given codes.
+// The chain is traversed as by Walk. NoCode never matches.
+func HasCode(err error, codes ...ErrorCode) bool {
+
+	found := false
+
+	Walk(err, func(e error) bool {
+		if c := codeOf(e); c != NoCode {
+			for _, code := range codes {
+				if c == code {
+					found = true
+					return false
+				}
+			}
 		}
-		err = cause.Cause()
-	}
-	return int(NoCode)
+		return true
+	})
+	return found
 }
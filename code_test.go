@@ -0,0 +1,161 @@
+package errors_test
+
+import (
+	"bytes"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/ihleven/errors"
+)
+
+func TestIsCode(t *testing.T) {
+
+	notFound := errors.NewWithCode(errors.NotFound, "no such user %q", "bob")
+
+	tests := []struct {
+		name string
+		err  error
+		code errors.ErrorCode
+		want bool
+	}{
+		{"nil", nil, errors.NotFound, false},
+		{"fundamental", notFound, errors.NotFound, true},
+		{"other code", notFound, errors.BadRequest, false},
+		{"wrapped", errors.Wrap(notFound, "loading user"), errors.NotFound, true},
+		{"foreign wrapper", fmt.Errorf("handler: %w", errors.Wrap(notFound, "loading user")), errors.NotFound, true},
+		{"joined", stderrors.Join(stderrors.New("first"), notFound), errors.NotFound, true},
+		{"no code", errors.New("plain"), errors.NoCode, false},
+	}
+	for _, tt := range tests {
+		if got := errors.Is(tt.err, tt.code); got != tt.want {
+			t.Errorf("%s: Is(err, %d) = %v, want %v", tt.name, tt.code, got, tt.want)
+		}
+	}
+}
+
+func TestHasCode(t *testing.T) {
+
+	err := errors.Wrap(errors.NewWithCode(errors.BadRequest, "invalid id"), "parsing request")
+
+	if !errors.HasCode(err, errors.NotFound, errors.BadRequest) {
+		t.Errorf("HasCode(err, NotFound, BadRequest) = false, want true")
+	}
+	if errors.HasCode(err, errors.NotFound) {
+		t.Errorf("HasCode(err, NotFound) = true, want false")
+	}
+	if errors.HasCode(err) {
+		t.Errorf("HasCode(err) without codes = true, want false")
+	}
+}
+
+func TestCodeThroughForeignWrappers(t *testing.T) {
+
+	inner := errors.NewWithCode(errors.NotFound, "no such file")
+	err := errors.Wrap(fmt.Errorf("reading config: %w", inner), "starting server")
+
+	if got := errors.Code(err); got != int(errors.NotFound) {
+		t.Errorf("Code(err) = %d, want %d", got, errors.NotFound)
+	}
+	if got := errors.GetCauseCode(err); got != int(errors.NotFound) {
+		t.Errorf("GetCauseCode(err) = %d, want %d", got, errors.NotFound)
+	}
+	if got := errors.Cause(err); got != errors.Cause(inner) {
+		t.Errorf("Cause(err) = %v, want %v", got, errors.Cause(inner))
+	}
+
+	joined := stderrors.Join(errors.New("uncoded"), errors.NewWithCode(errors.BadRequest, "invalid"))
+	if got := errors.Code(joined); got != int(errors.BadRequest) {
+		t.Errorf("Code(joined) = %d, want %d", got, errors.BadRequest)
+	}
+}
+
+type cyclic struct{ next error }
+
+func (c *cyclic) Error() string { return "cyclic" }
+func (c *cyclic) Unwrap() error { return c.next }
+
+func TestWalkCycle(t *testing.T) {
+
+	a := &cyclic{}
+	b := &cyclic{next: a}
+	a.next = b
+
+	if n := len(errors.Chain(a)); n != 2 {
+		t.Errorf("len(Chain(a)) = %d, want 2", n)
+	}
+	if got := errors.Code(a); got != int(errors.NoCode) {
+		t.Errorf("Code(a) = %d, want NoCode", got)
+	}
+	errors.Cause(a)
+}
+
+// box is a comparable error type, which is not hashable when holding multi.
+type box struct{ err error }
+
+func (b box) Error() string { return "box: " + b.err.Error() }
+func (b box) Unwrap() error { return b.err }
+
+type multi []error
+
+func (m multi) Error() string   { return "multi" }
+func (m multi) Unwrap() []error { return m }
+
+func TestWalkIncomparable(t *testing.T) {
+
+	err := box{multi{errors.NewWithCode(errors.NotFound, "missing"), stderrors.New("plain")}}
+
+	if n := len(errors.Chain(err)); n != 4 {
+		t.Errorf("len(Chain(err)) = %d, want 4", n)
+	}
+	if errors.Is(err, box{multi{}}) || errors.Is(err, stderrors.ErrUnsupported) {
+		t.Errorf("Is(err, ...) = true, want false")
+	}
+	if !errors.Is(err, errors.NotFound) || errors.Code(err) != int(errors.NotFound) {
+		t.Errorf("Code(err) = %d, want NotFound", errors.Code(err))
+	}
+	if got := errors.Cause(err); got.Error() != "missing" {
+		t.Errorf("Cause(err) = %v, want missing", got)
+	}
+	if wrapped := errors.Wrap(err, "x"); fmt.Sprintf("%+v", wrapped) == "" {
+		t.Errorf("%%+v of Wrap(err) is empty")
+	}
+}
+
+func TestCodeAsError(t *testing.T) {
+
+	w := errors.Wrap(errors.NotFound, "lookup")
+	if !errors.Is(w, errors.NotFound) || !errors.HasCode(w, errors.NotFound) {
+		t.Errorf("Is = %v, HasCode = %v, want both true", errors.Is(w, errors.NotFound), errors.HasCode(w, errors.NotFound))
+	}
+	if got := errors.Code(w); got != int(errors.NotFound) {
+		t.Errorf("Code = %d, want %d", got, errors.NotFound)
+	}
+	if got := errors.GetCauseCode(w); got != int(errors.NotFound) {
+		t.Errorf("GetCauseCode = %d, want %d", got, errors.NotFound)
+	}
+	if got := w.Error(); got != "lookup: error code 404" {
+		t.Errorf("Error() = %q", got)
+	}
+
+	// formatting codes is unchanged by ErrorCode implementing error
+	for format, want := range map[string]string{"%v": "404", "%d": "404", "%s": "404", "%x": "194", "%5v": "  404", "%+v": "404"} {
+		if got := fmt.Sprintf(format, errors.NotFound); got != want {
+			t.Errorf("Sprintf(%q, NotFound) = %q, want %q", format, got, want)
+		}
+	}
+
+	// and so is logging and encoding them
+	var buf bytes.Buffer
+	slog.New(slog.NewJSONHandler(&buf, nil)).Info("req", "code", errors.NotFound)
+	slog.New(slog.NewTextHandler(&buf, nil)).Info("req", "code", errors.NotFound)
+	if got := buf.String(); !strings.Contains(got, `"code":404}`) || !strings.Contains(got, " code=404\n") {
+		t.Errorf("logged %s", got)
+	}
+	if b, err := json.Marshal(map[string]interface{}{"code": errors.NotFound}); err != nil || string(b) != `{"code":404}` {
+		t.Errorf("json.Marshal = %s, %v", b, err)
+	}
+}
@@ -0,0 +1,66 @@
+package errors
+
+import (
+	"context"
+	"sync"
+)
+
+// ContextExtractor returns a value stored in a context, e.g. a request or trace ID,
+// and whether the context holds one.
+type ContextExtractor func(ctx context.Context) (interface{}, bool)
+
+var extractors struct {
+	sync.RWMutex
+	keys []string
+	fns  []ContextExtractor
+}
+
+// RegisterContextExtractor registers a function extracting a value from contexts given
+// to NewCtx, WrapCtx or WithContext. The extracted value is attached to the error as
+// field key. Registering a key again replaces its extractor.
+// Extractors are typically registered in init functions.
+func RegisterContextExtractor(key string, extract ContextExtractor) {
+
+	extractors.Lock()
+	defer extractors.Unlock()
+
+	for i, k := range extractors.keys {
+		if k == key {
+			extractors.fns[i] = extract
+			return
+		}
+	}
+	extractors.keys = append(extractors.keys, key)
+	extractors.fns = append(extractors.fns, extract)
+}
+
+// WithContext attaches the values the registered extractors find in ctx as fields to the error.
+// See RegisterContextExtractor.
+func WithContext(ctx context.Context) Option {
+	return func(o *options) {
+		if ctx == nil {
+			return
+		}
+
+		extractors.RLock()
+		defer extractors.RUnlock()
+
+		for i, extract := range extractors.fns {
+			if value, ok := extract(ctx); ok {
+				o.fields = append(o.fields, field{extractors.keys[i], value})
+			}
+		}
+	}
+}
+
+// NewCtx behaves like New. Additionally it attaches the values extracted from ctx as fields.
+// See WithContext.
+func NewCtx(ctx context.Context, format string, args ...interface{}) error {
+	return newError(NoCode, format, append([]interface{}{WithContext(ctx)}, args...))
+}
+
+// WrapCtx behaves like Wrap. Additionally it attaches the values extracted from ctx as fields.
+// See WithContext.
+func WrapCtx(ctx context.Context, err error, args ...interface{}) error {
+	return Wrap(err, append([]interface{}{WithContext(ctx), SkipFrames(1)}, args...)...)
+}
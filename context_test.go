@@ -0,0 +1,76 @@
+package errors_test
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"testing"
+
+	"github.com/ihleven/errors"
+)
+
+type requestIDKey struct{}
+
+func init() {
+	errors.RegisterContextExtractor("request_id", func(ctx context.Context) (interface{}, bool) {
+		id, ok := ctx.Value(requestIDKey{}).(string)
+		return id, ok
+	})
+}
+
+func TestNewCtx(t *testing.T) {
+
+	ctx := context.WithValue(context.Background(), requestIDKey{}, "req-1")
+
+	err := errors.NewCtx(ctx, "boom %d", 1)
+	if got := err.Error(); got != "boom 1" {
+		t.Errorf("Error() = %q, want %q", got, "boom 1")
+	}
+	if got := errors.Fields(err)["request_id"]; got != "req-1" {
+		t.Errorf("Fields()[request_id] = %v, want req-1", got)
+	}
+
+	err = errors.NewCtx(ctx, "boom", errors.Field("request_id", "explicit"))
+	if got := errors.Fields(err)["request_id"]; got != "explicit" {
+		t.Errorf("Fields()[request_id] = %v, want explicit field to win", got)
+	}
+
+	if fields := errors.Fields(errors.NewCtx(context.Background(), "boom")); fields != nil {
+		t.Errorf("Fields() = %v, want none", fields)
+	}
+}
+
+func TestWrapCtx(t *testing.T) {
+
+	ctx := context.WithValue(context.Background(), requestIDKey{}, "req-2")
+
+	err := errors.WrapCtx(ctx, fmt.Errorf("boom"), "handling")
+	if got := errors.Fields(err)["request_id"]; got != "req-2" {
+		t.Errorf("Fields()[request_id] = %v, want req-2", got)
+	}
+	if layer := errors.Layers(err)[0]; layer.Function != "TestWrapCtx" || path.Base(layer.File) != "context_test.go" {
+		t.Errorf("wrapped at %s (%s), want context_test.go (TestWrapCtx)", layer.File, layer.Function)
+	}
+
+	inner := errors.New("boom")
+	if err := errors.WrapCtx(context.Background(), inner); err != inner {
+		t.Errorf("WrapCtx() without message and values = %v, want err unchanged", err)
+	}
+}
+
+func TestContextCodes(t *testing.T) {
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := errors.Wrap(ctx.Err(), "waiting")
+	if got := errors.Code(err); got != int(errors.Canceled) {
+		t.Errorf("Code() = %d, want %d", got, errors.Canceled)
+	}
+	if !errors.Is(err, errors.Canceled) {
+		t.Errorf("Is(err, Canceled) = false, want true")
+	}
+	if got := errors.Code(fmt.Errorf("calling: %w", context.DeadlineExceeded)); got != int(errors.DeadlineExceeded) {
+		t.Errorf("Code() = %d, want %d", got, errors.DeadlineExceeded)
+	}
+}
@@ -0,0 +1,137 @@
+package errors
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Def defines a kind of error by a code and a message template over the parameter
+// struct T. Errors of the kind are created by New, which requires the parameters
+// at compile time, and recognized in any chain by Is and Extract:
+//
+//	type NotFoundParams struct {
+//		Resource string
+//		ID       int
+//	}
+//
+//	var ErrNotFound = errors.Define[NotFoundParams](errors.NotFound, "{Resource} {ID} not found")
+//
+//	err := ErrNotFound.New(NotFoundParams{"user", 42})
+//
+//	if p, ok := ErrNotFound.Extract(err); ok {
+//		// handle p.Resource and p.ID
+//	}
+//
+// A Def is meant to be a package level variable and must not be copied.
+type Def[T any] struct {
+	code     ErrorCode
+	template string
+	// parts are the literal texts of the template, fields the indexes of the fields of T
+	// between them
+	parts  []string
+	fields [][]int
+}
+
+// defined is the metadata attached to errors created by a Def.
+type defined[T any] struct {
+	def    *Def[T]
+	params T
+}
+
+// Define returns a Def of errors with the given code and message template. The template
+// refers to exported fields of the struct T in braces, like "{ID}", which are formatted
+// with %v. Define panics if T is not a struct or the template refers to a field T lacks,
+// so that mistakes surface when the package defining the error is initialized.
+func Define[T any](code ErrorCode, template string) *Def[T] {
+
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	if t.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("errors: Define of %q: parameters of type %s are not a struct", template, t))
+	}
+
+	d := &Def[T]{code: code, template: template}
+	rest := template
+	for {
+		start := strings.IndexByte(rest, '{')
+		if start < 0 {
+			break
+		}
+		end := strings.IndexByte(rest[start:], '}')
+		if end < 0 {
+			panic(fmt.Sprintf("errors: Define of %q: unclosed brace", template))
+		}
+		name := rest[start+1 : start+end]
+		f, ok := t.FieldByName(name)
+		if !ok || !f.IsExported() {
+			panic(fmt.Sprintf("errors: Define of %q: %s has no exported field %s", template, t, name))
+		}
+		d.parts = append(d.parts, rest[:start])
+		d.fields = append(d.fields, f.Index)
+		rest = rest[start+end+1:]
+	}
+	d.parts = append(d.parts, rest)
+	return d
+}
+
+// Code returns the code of the errors of d.
+func (d *Def[T]) Code() ErrorCode { return d.code }
+
+// String returns the template of d.
+func (d *Def[T]) String() string { return d.template }
+
+// New returns an error with the code of d and the message rendered from its template
+// and params, which can be recovered by Extract. Options are applied like by NewWithCode.
+func (d *Def[T]) New(params T, opts ...Option) error {
+
+	args := make([]interface{}, 0, len(opts)+2)
+	for _, opt := range opts {
+		args = append(args, opt)
+	}
+	args = append(args, Option(func(o *options) { o.defined = &defined[T]{d, params} }), d.message(params))
+
+	// called directly for the stack to start at the caller, as by New;
+	// the rendered message is an argument, as it may contain verbs
+	return newError(d.code, "%s", args)
+}
+
+// message renders the template of d with params.
+func (d *Def[T]) message(params T) string {
+
+	if len(d.fields) == 0 {
+		return d.parts[0]
+	}
+	v := reflect.ValueOf(params)
+	var b strings.Builder
+	for i, index := range d.fields {
+		b.WriteString(d.parts[i])
+		fmt.Fprint(&b, v.FieldByIndex(index).Interface())
+	}
+	b.WriteString(d.parts[len(d.parts)-1])
+	return b.String()
+}
+
+// Is reports whether an error of err's chain was created by d.
+func (d *Def[T]) Is(err error) bool {
+	_, ok := d.Extract(err)
+	return ok
+}
+
+// Extract returns the parameters of the outermost error of err's chain created by d,
+// false if there is none.
+func (d *Def[T]) Extract(err error) (T, bool) {
+
+	var params T
+	found := false
+
+	Walk(err, func(e error) bool {
+		if m, ok := e.(interface{ meta() *metadata }); ok {
+			if def, ok := m.meta().defined.(*defined[T]); ok && def.def == d {
+				params, found = def.params, true
+				return false
+			}
+		}
+		return true
+	})
+	return params, found
+}
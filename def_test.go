@@ -0,0 +1,86 @@
+package errors_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/ihleven/errors"
+)
+
+type notFoundParams struct {
+	Resource string
+	ID       int
+}
+
+var errNotFound = errors.Define[notFoundParams](errors.NotFound, "{Resource} {ID} not found")
+
+var errConflict = errors.Define[notFoundParams](409, "{Resource} {ID} exists, 100% sure")
+
+func TestDef(t *testing.T) {
+
+	requireStacks(t)
+	err := errNotFound.New(notFoundParams{"user", 42}, errors.Field("tenant", "acme"))
+	if got, want := err.Error(), "user 42 not found"; got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+	if !errors.HasCode(err, errors.NotFound) {
+		t.Errorf("error lacks code %d", errors.NotFound)
+	}
+	if got := errors.Fields(err)["tenant"]; got != "acme" {
+		t.Errorf("field tenant = %v, want acme", got)
+	}
+	if layer := errors.Layers(err)[0]; layer.Function != "TestDef" {
+		t.Errorf("error created in %s, want TestDef", layer.Function)
+	}
+	if got := errConflict.New(notFoundParams{"user", 1}).Error(); got != "user 1 exists, 100% sure" {
+		t.Errorf("Error() = %q", got)
+	}
+
+	chains := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"created", err, true},
+		{"wrapped", errors.Wrap(err, "loading"), true},
+		{"fmt.Errorf", fmt.Errorf("loading: %w", err), true},
+		{"other def", errConflict.New(notFoundParams{"user", 42}), false},
+		{"same code", errors.NewWithCode(errors.NotFound, "user 42 not found"), false},
+	}
+	for _, tt := range chains {
+		if got := errNotFound.Is(tt.err); got != tt.want {
+			t.Errorf("%s: Is = %v, want %v", tt.name, got, tt.want)
+		}
+		params, ok := errNotFound.Extract(tt.err)
+		if ok != tt.want || (ok && params != (notFoundParams{"user", 42})) {
+			t.Errorf("%s: Extract = %v, %v", tt.name, params, ok)
+		}
+	}
+
+	outer := errNotFound.New(notFoundParams{"order", 7})
+	if params, _ := errNotFound.Extract(errors.Wrap(outer, "%v", err)); params.ID != 7 {
+		t.Errorf("Extract = %v, want the outermost parameters", params)
+	}
+}
+
+func TestDefine(t *testing.T) {
+
+	for _, tt := range []struct {
+		name   string
+		define func()
+	}{
+		{"unknown field", func() { errors.Define[notFoundParams](errors.NotFound, "{Name} not found") }},
+		{"unclosed brace", func() { errors.Define[notFoundParams](errors.NotFound, "{ID not found") }},
+		{"no struct", func() { errors.Define[string](errors.NotFound, "not found") }},
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%s: Define did not panic", tt.name)
+				}
+			}()
+			tt.define()
+		}()
+	}
+}
@@ -12,6 +12,19 @@
 // The errors.Wrap function returns a new error that adds context in form of caller information and a message to the
 // original error. It also records a stack trace at the point Wrap is called if no previously done so by the package.
 //
+// Attaching metadata to an error
+//
+// New, NewWithCode and Wrap accept Options among their variadic arguments. Options
+// attach a code, key value fields, a severity or a public message to the error:
+//
+//	errors.Wrap(err, "loading user %d", id, errors.WithCode(errors.NotFound), errors.Field("user", id))
+//
+// Errors carrying structured parameters are declared with Define, binding a code and a
+// message template to a parameter struct. The parameters are checked by the compiler
+// where the error is created and recovered from any chain by Def.Extract:
+//
+//	var ErrNotFound = errors.Define[NotFoundParams](errors.NotFound, "{Resource} {ID} not found")
+//
 // Retrieving the cause of an error
 //
 // Using errors.Wrap constructs a stack of errors, adding context to the
@@ -19,13 +32,14 @@
 // to reverse the operation of errors.Wrap to retrieve the original error
 // for inspection. Any error value which implements this interface
 //
-//     type causer interface {
+//     type Causer interface {
 //             Cause() error
 //     }
 //
 // can be inspected by errors.Cause. errors.Cause will recursively retrieve
-// the topmost error that does not implement causer, which is assumed to be
-// the original cause. For example:
+// the topmost error that does not implement Causer, which is assumed to be
+// the original cause. Errors implementing Unwrap, like those returned by
+// fmt.Errorf("%w"), are followed the same way. For example:
 //
 //     switch err := errors.Cause(err).(type) {
 //     case *MyError:
@@ -34,8 +48,12 @@
 //             // unknown error
 //     }
 //
-// Although the causer interface is not exported by this package, it is
-// considered a part of its stable public interface.
+// The Causer interface is part of the stable public interface of this package.
+// Errors of other packages implementing it, as well as the Coder and StackTracer
+// interfaces, take part in Cause, Code and the formatting of chains alike.
+//
+// To inspect every error of a chain instead of only its cause, use errors.Walk
+// or errors.Chain. Code, HasCode, Is and As are built on the same traversal.
 //
 // Formatted printing of errors
 //
@@ -54,7 +72,7 @@
 // Both, New and Wrap, record a stack trace at the point they are
 // invoked. This information can be retrieved with the following interface:
 //
-//     type stackTracer interface {
+//     type StackTracer interface {
 //             StackTrace() errors.StackTrace
 //     }
 //
@@ -66,15 +84,23 @@
 // the fmt.Formatter interface that can be used for printing information about
 // the stack trace of this error. For example:
 //
-//     if err, ok := err.(stackTracer); ok {
+//     if err, ok := err.(errors.StackTracer); ok {
 //             for _, f := range err.StackTrace() {
 //                     fmt.Printf("%+s:%d\n", f, f)
 //             }
 //     }
 //
-// Although the stackTracer interface is not exported by this package, it is
-// considered a part of its stable public interface.
+// The StackTracer interface is part of the stable public interface of this package.
+// To retrieve the stack recorded closest to where an error originated, no matter
+// how deep in the chain, use errors.GetStackTrace.
 //
 // See the documentation for Frame.Format for more details.
+//
+// Recording stack traces can be limited with SetStackMode and SetStackSampling,
+// or disabled entirely by building with the tag errors_nostack. Errors without
+// a stack trace are formatted without one.
+//
+// Goroutines launched with errors.Go record the stack of their launcher, which
+// errors created inside them print as a "created by" section.
 package errors
 
@@ -0,0 +1,92 @@
+package errors
+
+import (
+	"reflect"
+	"runtime"
+	"runtime/debug"
+	"strconv"
+	"sync"
+	"sync/atomic"
+)
+
+// StackEncoding controls how Layer.MarshalJSON encodes stack traces.
+type StackEncoding int32
+
+const (
+	// EncodeSymbols encodes frames as function name, file and line. This is the default.
+	EncodeSymbols StackEncoding = iota
+	// EncodePCs encodes frames as raw program counters, like "0x4a1b2f", along with the
+	// BuildInfo needed to symbolize them later against the executable, e.g. with package
+	// github.com/ihleven/errors/symbolize. It avoids resolving symbols when encoding.
+	EncodePCs
+)
+
+var stackEncoding atomic.Int32
+
+// SetStackEncoding sets how stack traces are encoded as JSON from now on.
+func SetStackEncoding(enc StackEncoding) {
+	stackEncoding.Store(int32(enc))
+}
+
+// BuildInfo identifies the executable program counters were recorded in.
+type BuildInfo struct {
+	ID       string `json:"id,omitempty"`       // Go build ID of the executable, see SetBuildID
+	Module   string `json:"module,omitempty"`   // path of the main module
+	Version  string `json:"version,omitempty"`  // version of the main module
+	Revision string `json:"revision,omitempty"` // version control revision the executable was built from
+	// Anchor is the address of the function AnchorFunction in the running process.
+	// Comparing it to the address of the function in the executable yields the offset
+	// position independent executables were loaded at.
+	Anchor         uintptr `json:"anchor"`
+	AnchorFunction string  `json:"anchor_function"`
+}
+
+var buildInfo struct {
+	once sync.Once
+	info BuildInfo
+}
+
+// Build returns the BuildInfo of the running executable.
+func Build() BuildInfo {
+	buildInfo.once.Do(func() {
+		info := &buildInfo.info
+		info.Anchor = reflect.ValueOf(anchor).Pointer()
+		info.AnchorFunction = runtime.FuncForPC(info.Anchor).Name()
+		if bi, ok := debug.ReadBuildInfo(); ok {
+			info.Module, info.Version = bi.Main.Path, bi.Main.Version
+			for _, s := range bi.Settings {
+				if s.Key == "vcs.revision" {
+					info.Revision = s.Value
+				}
+			}
+		}
+	})
+	info := buildInfo.info
+	if id := buildID.Load(); id != nil {
+		info.ID = *id
+	}
+	return info
+}
+
+var buildID atomic.Pointer[string]
+
+// SetBuildID sets the build ID of the running executable returned by Build. It is called
+// by package github.com/ihleven/errors/buildid when imported, which reads the ID from
+// the executable. The package is not imported here to spare programs its size.
+func SetBuildID(id string) {
+	buildID.Store(&id)
+}
+
+// anchor is the function whose address BuildInfo records.
+//
+//go:noinline
+func anchor() {}
+
+// pcTexts returns the program counters of st in hexadecimal.
+func pcTexts(st StackTrace) []string {
+	var texts []string
+	for _, f := range st {
+		texts = append(texts, "0x"+strconv.FormatUint(uint64(f), 16))
+	}
+	return texts
+}
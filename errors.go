@@ -3,54 +3,105 @@ package errors
 import (
 	"fmt"
 	"io"
+	"strings"
 )
 
 // New returns an error with the supplied message.
 // New also records the stack trace at the point it was called.
 // In case a format string is given, New formats
 // according to a format specifier and returns the string as a value that satisfies error.
+// Options like WithCode or Field may be given among args to attach metadata to the error.
 func New(format string, args ...interface{}) error {
-
-	return &withStack{
-		&fundamental{
-			msg:  fmt.Sprintf(format, args...),
-			code: NoCode,
-		},
-		callers(),
-	}
+	return newError(NoCode, format, args)
 }
 
 // NewWithCode behaves like New. Additionally it attaches the given code to the returned error.
+// It is a shorthand for New(format, args..., WithCode(code)), apart from
+// a WithCode option among args taking precedence.
 func NewWithCode(code ErrorCode, format string, args ...interface{}) error {
+	return newError(code, format, args)
+}
+
+// newError implements New and NewWithCode. It has to be called directly by them
+// for the recorded stack trace to start at their caller.
+// The error is created with a single allocation, apart from the message and the stack.
+func newError(code ErrorCode, format string, args []interface{}) error {
+
+	opts, args := parseArgs(code, args)
+
+	var buf [initialDepth]uintptr
+	err := newFundamental(callers(1+opts.skip, &buf))
+	err.stack.created = err.stack.creator()
+	err.msg = sprintf(format, args)
+	err.metadata = opts.metadata
+
+	observe(EventNew, err)
+	return err
+}
 
-	return &withStack{
-		&fundamental{
-			msg:  fmt.Sprintf(format, args...),
-			code: code,
-		},
-		callers(),
+// newFundamental allocates a fundamental together with room for the program counters
+// of its stack, so that creating an error takes a single allocation apart from the message.
+// The room is rounded up to 8, 16 or 32 program counters, deeper stacks are referenced.
+func newFundamental(pcs []uintptr, more int) *fundamental {
+
+	var f *fundamental
+	switch n := len(pcs); {
+	case n == 0:
+		return &fundamental{}
+	case n <= 8:
+		x := &struct {
+			fundamental
+			pcs [8]uintptr
+		}{}
+		f = &x.fundamental
+		f.stack.pcs = x.pcs[:0]
+	case n <= 16:
+		x := &struct {
+			fundamental
+			pcs [16]uintptr
+		}{}
+		f = &x.fundamental
+		f.stack.pcs = x.pcs[:0]
+	case n <= initialDepth:
+		x := &struct {
+			fundamental
+			pcs [initialDepth]uintptr
+		}{}
+		f = &x.fundamental
+		f.stack.pcs = x.pcs[:0]
+	default:
+		return &fundamental{stack: stack{pcs: append([]uintptr(nil), pcs...), more: more}}
 	}
+	f.stack.pcs = append(f.stack.pcs, pcs...)
+	f.stack.more = more
+	return f
+}
+
+// sprintf formats according to format, skipping fmt.Sprintf without args and verbs.
+func sprintf(format string, args []interface{}) string {
+	if len(args) == 0 && strings.IndexByte(format, '%') < 0 {
+		return format
+	}
+	return fmt.Sprintf(format, args...)
 }
 
 // fundamental is an error that has a message and a stack, but no caller.
 type fundamental struct {
 	msg string
-	// *stack
-	code ErrorCode
+	stack
+	metadata
 }
 
 func (f *fundamental) Error() string { return f.msg }
 
-func (f *fundamental) Code() int { return int(f.code) }
-
 func (f *fundamental) Format(s fmt.State, verb rune) {
 	switch verb {
 	case 'v':
-		// if s.Flag('+') {
-		// 	io.WriteString(s, f.msg)
-		// 	// f.stack.Format(s, verb)
-		// 	return
-		// }
+		if s.Flag('+') || s.Flag('#') {
+			io.WriteString(s, f.msg)
+			f.stack.Format(s, verb)
+			return
+		}
 		fallthrough
 	case 's':
 		io.WriteString(s, f.msg)
@@ -71,9 +122,10 @@ func (f *fundamental) Format(s fmt.State, verb rune) {
 // 	}
 // }
 
+// withStack attaches a stack to an error not created by this package.
 type withStack struct {
 	error
-	*stack
+	stack
 }
 
 func (w *withStack) Cause() error { return w.error }
@@ -86,6 +138,13 @@ func (w *withStack) Format(s fmt.State, verb rune) {
 	case 'v':
 		if s.Flag('+') || s.Flag('#') {
 			fmt.Fprintf(s, "%+v", w.Cause())
+			// a formatter is expected to print the stacks further down the chain
+			if _, ok := w.error.(fmt.Formatter); ok {
+				if inner, ok := innerStack(w.error); ok {
+					w.stack.formatUnique(s, inner)
+					return
+				}
+			}
 			w.stack.Format(s, verb)
 			return
 		}
@@ -103,6 +162,9 @@ func (w *withStack) Format(s fmt.State, verb rune) {
 
 // Wrap returns an error annotating err with a stack trace
 // at the point Wrap is called, and the supplied message.
+// The stack trace is only recorded if there is none anywhere in err's chain yet.
+// If the first of args is a string, it is used as format string for the remaining args.
+// Options like WithCode or Field may be given among args to attach metadata to the wrapping layer.
 // If err is nil, Wrap returns nil.
 func Wrap(err error, args ...interface{}) error {
 
@@ -110,41 +172,50 @@ func Wrap(err error, args ...interface{}) error {
 		return nil
 	}
 
+	opts, args := parseArgs(NoCode, args)
+	stacked := false
+
 	switch err.(type) {
-	case *withStack, *withMessage:
+	case *fundamental, *withStack, *withMessage:
 	// nothing to do here
 	default:
-		// no stack as of yet, adding one
-		err = &withStack{
-			err,
-			callers(),
+		if _, ok := innerStack(err); ok {
+			// a stack further down the chain
+			break
 		}
+		// no stack as of yet, adding one
+		var buf [initialDepth]uintptr
+		pcs, more := callers(opts.skip, &buf)
+		w := &withStack{err, stack{pcs: append([]uintptr(nil), pcs...), more: more}}
+		w.stack.created = w.stack.creator()
+		err = w
+		stacked = true
 	}
 
-	if len(args) == 0 {
+	if len(args) == 0 && opts.isZero() {
+		if stacked {
+			observe(EventWrap, err)
+		}
 		return err
 	}
 
 	wrapped := &withMessage{
-		cause: err,
+		cause:    err,
+		metadata: opts.metadata,
 		// msg:   fmt.Sprint(args...),
 	}
 
-	switch arg := args[0].(type) {
-	case string:
-		wrapped.msg = fmt.Sprintf(arg, args[1:]...)
-		// default:
-		// 	msg = fmt.Sprint(args...)
+	if len(args) > 0 {
+		switch arg := args[0].(type) {
+		case string:
+			wrapped.msg = sprintf(arg, args[1:])
+			// default:
+			// 	msg = fmt.Sprint(args...)
+		}
 	}
 
-	
-	file, function, line, ok := caller()
-	if ok {
-		// fmt.Printf("wrapping:  %s:%d\n", file, line)
-		wrapped.file = file
-		wrapped.function = function
-		wrapped.line = line
-	}
+	wrapped.frame = caller(opts.skip)
+	observe(EventWrap, wrapped)
 	return wrapped
 }
 
@@ -164,11 +235,10 @@ func Wrap(err error, args ...interface{}) error {
 // }
 
 type withMessage struct {
-	cause    error
-	msg      string
-	function string // function initiating withMessage
-	file     string // file initiating withMessage
-	line     int    // line initiating withMessage
+	cause error
+	msg   string
+	frame Frame // frame initiating withMessage, resolved to file, function and line when needed
+	metadata
 }
 
 func (w *withMessage) Error() string {
@@ -190,12 +260,14 @@ func (w *withMessage) Format(s fmt.State, verb rune) {
 			// fmt.Fprintf(s, "%+v\n", w.Cause())
 			// io.WriteString(s, w.msg)
 			// return
+			file, function, line := location(w.frame)
 			io.WriteString(s, w.msg)
-			fmt.Fprintf(s, "\n\t--- at %s:%d (%s)", w.file, w.line, w.function)
+			fmt.Fprintf(s, "\n\t--- at %s:%d (%s)", file, line, function)
 			if e, ok := w.Cause().(*withMessage); !ok || (ok && e.msg != "") {
 				fmt.Fprintf(s, "\nCaused by: ")
 			}
-			fmt.Fprintf(s, "%+v\n", w.Cause())
+			formatCause(s, w.Cause())
+			io.WriteString(s, "\n")
 
 			return
 		}
@@ -205,28 +277,57 @@ func (w *withMessage) Format(s fmt.State, verb rune) {
 	}
 }
 
+// formatCause prints err in the extended format. Errors implementing fmt.Formatter are
+// expected to print the stacks of their chain. For other errors %+v prints the message
+// only, so the stacks further down the chain are printed here, see chainStacks.
+func formatCause(s fmt.State, err error) {
+	fmt.Fprintf(s, "%+v", err)
+	if _, ok := err.(fmt.Formatter); ok {
+		return
+	}
+	var first []uintptr
+	for i, st := range chainStacks(err) {
+		if i == 0 {
+			st.Format(s, 'v')
+			first = st.pcs
+			continue
+		}
+		st.formatUnique(s, first)
+	}
+}
+
 // Cause returns the underlying cause of the error, if possible.
-// An error value has a cause if it implements the following
-// interface:
+// An error value has a cause if it implements one of the following
+// interfaces:
+//
+//	type causer interface {
+//	       Cause() error
+//	}
 //
-//     type causer interface {
-//            Cause() error
-//     }
+//	type wrapper interface {
+//	       Unwrap() error
+//	}
 //
-// If the error does not implement Cause, the original error will
-// be returned. If the error is nil, nil will be returned without further
+// Errors wrapping multiple errors (Unwrap() []error) are followed along their
+// first branch. Cause follows the chain until it reaches an error without a cause
+// and returns it. If the error is nil, nil will be returned without further
 // investigation.
 func Cause(err error) error {
-	type causer interface {
-		Cause() error
-	}
+
+	seen := make(map[error]bool)
 
 	for err != nil {
-		cause, ok := err.(causer)
-		if !ok {
+		if hashable(err) {
+			if seen[err] {
+				break
+			}
+			seen[err] = true
+		}
+		next := causes(err)
+		if len(next) == 0 {
 			break
 		}
-		err = cause.Cause()
+		err = next[0]
 	}
 	return err
 }
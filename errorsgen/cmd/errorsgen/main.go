@@ -0,0 +1,65 @@
+// Command errorsgen generates Go code for a catalog of errors declared in YAML or JSON,
+// see package github.com/ihleven/errors/errorsgen:
+//
+//	errorsgen [-out file] [-md file] [-package name] catalog.yaml
+//
+// The code is written to the file named like the catalog with the suffix _gen.go,
+// unless -out is given. -md writes the Markdown documentation of the catalog as well.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ihleven/errors/errorsgen"
+)
+
+func main() {
+	if err := run(os.Args[1:]); err != nil {
+		fmt.Fprintln(os.Stderr, "errorsgen:", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string) error {
+
+	flags := flag.NewFlagSet("errorsgen", flag.ContinueOnError)
+	out := flags.String("out", "", "file the code is written to, defaults to the catalog's name with the suffix _gen.go")
+	md := flags.String("md", "", "file the Markdown documentation is written to")
+	pkg := flags.String("package", os.Getenv("GOPACKAGE"), "name of the package, unless named by the catalog")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return fmt.Errorf("usage: errorsgen [-out file] [-md file] [-package name] catalog.yaml")
+	}
+	in := flags.Arg(0)
+	if *out == "" {
+		*out = strings.TrimSuffix(in, filepath.Ext(in)) + "_gen.go"
+	}
+
+	f, err := os.Open(in)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	catalog, err := errorsgen.Parse(f)
+	if err != nil {
+		return err
+	}
+
+	code, err := errorsgen.Generate(catalog, filepath.Base(in), *pkg)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(*out, code, 0o644); err != nil {
+		return err
+	}
+	if *md != "" {
+		return os.WriteFile(*md, errorsgen.Markdown(catalog), 0o644)
+	}
+	return nil
+}
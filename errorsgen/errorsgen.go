@@ -0,0 +1,358 @@
+// Package errorsgen generates Go code and documentation for a catalog of errors of
+// package github.com/ihleven/errors, declared in YAML or JSON:
+//
+//	package: orders
+//	errors:
+//	  - name: OrderNotFound
+//	    code: 4041
+//	    category: client
+//	    http: 404
+//	    grpc: NotFound
+//	    severity: debug
+//	    retry: permanent
+//	    message: "order {id} not found in store {store}"
+//	    params:
+//	      - {name: id, type: int}
+//	      - {name: store, type: string}
+//	    doc: The order does not exist or was deleted.
+//
+// For each error, a code constant CodeOrderNotFound and a constructor
+//
+//	func NewOrderNotFound(id int, store string, opts ...errors.Option) error
+//
+// are generated, the code is registered with errors.RegisterCode, and HTTPStatus and
+// GRPCCode map errors to the statuses given. The parameters of the message are attached
+// to the error as fields as well. Markdown documents the catalog.
+//
+// The command errorsgen runs the generator, typically by go generate:
+//
+//	//go:generate go run github.com/ihleven/errors/errorsgen/cmd/errorsgen -md ERRORS.md catalog.yaml
+package errorsgen
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/token"
+	"go/types"
+	"io"
+	"regexp"
+	"strings"
+	"text/template"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Catalog is a catalog of errors.
+type Catalog struct {
+	Package string  `yaml:"package"` // name of the package generated, defaults to the package go generate runs in
+	Errors  []Error `yaml:"errors"`
+}
+
+// Error declares an error of a catalog.
+type Error struct {
+	Name     string  `yaml:"name"`     // Go name of the error, e.g. "OrderNotFound"
+	Code     int     `yaml:"code"`     // numeric code
+	Category string  `yaml:"category"` // category registered for the code
+	HTTP     int     `yaml:"http"`     // HTTP status, 0 for none
+	GRPC     string  `yaml:"grpc"`     // name of the gRPC status code, e.g. "NotFound", empty for none
+	Severity string  `yaml:"severity"` // debug, info, warn, error or critical, empty for the default
+	Retry    string  `yaml:"retry"`    // temporary or permanent, empty if unknown
+	Message  string  `yaml:"message"`  // message template referring to params as {name}
+	Params   []Param `yaml:"params"`   // typed parameters of the message
+	Doc      string  `yaml:"doc"`      // documentation of the error
+}
+
+// Param is a parameter of an error message.
+type Param struct {
+	Name string `yaml:"name"` // Go name of the parameter, also the key of the field attached
+	Type string `yaml:"type"` // Go type of the parameter, see verbs
+}
+
+// verbs maps the supported parameter types to the verbs formatting them.
+var verbs = map[string]string{
+	"string": "%s", "bool": "%t", "error": "%v",
+	"int": "%d", "int8": "%d", "int16": "%d", "int32": "%d", "int64": "%d",
+	"uint": "%d", "uint8": "%d", "uint16": "%d", "uint32": "%d", "uint64": "%d",
+	"float32": "%g", "float64": "%g",
+	"time.Duration": "%s", "time.Time": "%s",
+}
+
+// grpcCodes maps the names of the gRPC status codes to their values.
+var grpcCodes = map[string]int{
+	"OK": 0, "Canceled": 1, "Unknown": 2, "InvalidArgument": 3, "DeadlineExceeded": 4,
+	"NotFound": 5, "AlreadyExists": 6, "PermissionDenied": 7, "ResourceExhausted": 8,
+	"FailedPrecondition": 9, "Aborted": 10, "OutOfRange": 11, "Unimplemented": 12,
+	"Internal": 13, "Unavailable": 14, "DataLoss": 15, "Unauthenticated": 16,
+}
+
+var (
+	severities = map[string]string{"debug": "SeverityDebug", "info": "SeverityInfo", "warn": "SeverityWarn", "error": "SeverityError", "critical": "SeverityCritical"}
+	retries    = map[string]string{"temporary": "RetryTemporary", "permanent": "RetryPermanent"}
+)
+
+// Parse reads a catalog in YAML or JSON and validates it.
+func Parse(r io.Reader) (*Catalog, error) {
+
+	var c Catalog
+	dec := yaml.NewDecoder(r)
+	dec.KnownFields(true)
+	if err := dec.Decode(&c); err != nil {
+		return nil, fmt.Errorf("errorsgen: reading catalog: %w", err)
+	}
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// reserved are the names used by the generated constructors, which parameters must not shadow.
+// Predeclared identifiers and the code constant of the error are reserved as well.
+var reserved = map[string]bool{"args": true, "opt": true, "opts": true, "errors": true, "time": true}
+
+var placeholderRe = regexp.MustCompile(`\{([^{}]*)\}`)
+
+// Validate checks the catalog for invalid names and values, duplicate names and codes,
+// and messages not matching their parameters.
+func (c *Catalog) Validate() error {
+
+	if c.Package != "" && !token.IsIdentifier(c.Package) {
+		return fmt.Errorf("errorsgen: invalid package name %q", c.Package)
+	}
+
+	names := make(map[string]bool)
+	codes := make(map[int]string)
+	for _, e := range c.Errors {
+		fail := func(format string, args ...interface{}) error {
+			return fmt.Errorf("errorsgen: error %s: %s", e.Name, fmt.Sprintf(format, args...))
+		}
+		switch {
+		case !token.IsIdentifier(e.Name) || !token.IsExported(e.Name):
+			return fail("name is not an exported Go identifier")
+		case names[e.Name]:
+			return fail("declared twice")
+		case codes[e.Code] != "":
+			return fail("code %d is the code of %s already", e.Code, codes[e.Code])
+		case e.Code <= 0 || e.Code >= 1<<16-1:
+			return fail("code %d is out of range", e.Code)
+		case e.HTTP != 0 && (e.HTTP < 100 || e.HTTP > 599):
+			return fail("invalid HTTP status %d", e.HTTP)
+		case e.GRPC != "" && !hasKey(grpcCodes, e.GRPC):
+			return fail("unknown gRPC code %q", e.GRPC)
+		case e.Severity != "" && severities[e.Severity] == "":
+			return fail("unknown severity %q", e.Severity)
+		case e.Retry != "" && retries[e.Retry] == "":
+			return fail("unknown retryability %q", e.Retry)
+		case e.Message == "":
+			return fail("message missing")
+		}
+		names[e.Name], codes[e.Code] = true, e.Name
+
+		params := make(map[string]bool)
+		for _, p := range e.Params {
+			switch {
+			case !token.IsIdentifier(p.Name):
+				return fail("invalid parameter name %q", p.Name)
+			case reserved[p.Name] || types.Universe.Lookup(p.Name) != nil || p.Name == "Code"+e.Name:
+				return fail("parameter name %s is reserved by the generated code", p.Name)
+			case params[p.Name]:
+				return fail("parameter %s declared twice", p.Name)
+			case verbs[p.Type] == "":
+				return fail("parameter %s has unsupported type %q", p.Name, p.Type)
+			}
+			params[p.Name] = true
+		}
+		used := make(map[string]bool)
+		for _, m := range placeholderRe.FindAllStringSubmatch(e.Message, -1) {
+			if !params[m[1]] {
+				return fail("message refers to unknown parameter {%s}", m[1])
+			}
+			used[m[1]] = true
+		}
+		for _, p := range e.Params {
+			if !used[p.Name] {
+				return fail("parameter %s is not used by the message", p.Name)
+			}
+		}
+	}
+	return nil
+}
+
+func hasKey(m map[string]int, key string) bool {
+	_, ok := m[key]
+	return ok
+}
+
+// format returns the message of e as format string for its params.
+func (e Error) format() string {
+	types := make(map[string]string)
+	for _, p := range e.Params {
+		types[p.Name] = p.Type
+	}
+	msg := strings.ReplaceAll(e.Message, "%", "%%")
+	return placeholderRe.ReplaceAllStringFunc(msg, func(m string) string {
+		return verbs[types[m[1:len(m)-1]]]
+	})
+}
+
+// Generate returns the Go code for the catalog c, read from the file source. pkg is
+// the package name used if the catalog does not name one.
+func Generate(c *Catalog, source, pkg string) ([]byte, error) {
+
+	if c.Package != "" {
+		pkg = c.Package
+	}
+	if pkg == "" {
+		return nil, fmt.Errorf("errorsgen: no package name")
+	}
+
+	usesTime := false
+	for _, e := range c.Errors {
+		for _, p := range e.Params {
+			usesTime = usesTime || strings.HasPrefix(p.Type, "time.")
+		}
+	}
+
+	var buf bytes.Buffer
+	err := codeTemplate.Execute(&buf, map[string]interface{}{
+		"Source":   source,
+		"Package":  pkg,
+		"UsesTime": usesTime,
+		"Errors":   c.Errors,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("errorsgen: %w", err)
+	}
+	code, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("errorsgen: formatting generated code: %w", err)
+	}
+	return code, nil
+}
+
+// Markdown returns the documentation of the catalog c.
+func Markdown(c *Catalog) []byte {
+
+	var buf bytes.Buffer
+	title := "Errors"
+	if c.Package != "" {
+		title = "Errors of package " + c.Package
+	}
+	fmt.Fprintf(&buf, "# %s\n\n", title)
+	fmt.Fprintf(&buf, "| Code | Name | Category | HTTP | gRPC | Severity | Retry | Message |\n")
+	fmt.Fprintf(&buf, "|-----:|------|----------|-----:|------|----------|-------|---------|\n")
+	for _, e := range c.Errors {
+		fmt.Fprintf(&buf, "| %d | [%s](#%s) | %s | %s | %s | %s | %s | `%s` |\n",
+			e.Code, e.Name, strings.ToLower(e.Name), e.Category, orEmpty(e.HTTP), e.GRPC, e.Severity, e.Retry, e.Message)
+	}
+	for _, e := range c.Errors {
+		fmt.Fprintf(&buf, "\n## %s\n\n", e.Name)
+		if e.Doc != "" {
+			fmt.Fprintf(&buf, "%s\n\n", strings.TrimSpace(e.Doc))
+		}
+		fmt.Fprintf(&buf, "Code %d, created by `New%s(", e.Code, e.Name)
+		for i, p := range e.Params {
+			if i > 0 {
+				buf.WriteString(", ")
+			}
+			fmt.Fprintf(&buf, "%s %s", p.Name, p.Type)
+		}
+		buf.WriteString(")`.\n")
+	}
+	return buf.Bytes()
+}
+
+func orEmpty(n int) string {
+	if n == 0 {
+		return ""
+	}
+	return fmt.Sprint(n)
+}
+
+var codeTemplate = template.Must(template.New("code").Funcs(template.FuncMap{
+	"comment": func(s string) string {
+		return strings.ReplaceAll(strings.TrimSpace(s), "\n", "\n// ")
+	},
+	"format":   func(e Error) string { return fmt.Sprintf("%q", e.format()) },
+	"severity": func(s string) string { return severities[s] },
+	"retry":    func(s string) string { return retries[s] },
+	"grpc":     func(s string) int { return grpcCodes[s] },
+}).Parse(`// Code generated by errorsgen from {{.Source}}. DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+{{- if .UsesTime}}
+	"time"
+{{end}}
+	"github.com/ihleven/errors"
+)
+
+// Codes of the errors of the catalog.
+const (
+{{- range .Errors}}
+	// Code{{.Name}} is the code of the errors returned by New{{.Name}}.
+	Code{{.Name}} errors.ErrorCode = {{.Code}}
+{{- end}}
+)
+
+func init() {
+{{- range .Errors}}
+	errors.RegisterCode(Code{{.Name}}, errors.CodeInfo{Name: {{printf "%q" .Name}}, Category: {{printf "%q" .Category}}
+		{{- with .Severity}}, Severity: errors.{{severity .}}{{end}}
+		{{- with .Retry}}, Retry: errors.{{retry .}}{{end}}})
+{{- end}}
+}
+{{range .Errors}}
+// New{{.Name}} returns an error with the code Code{{.Name}} and the message
+//
+//	{{.Message}}
+//
+{{- with .Doc}}
+// {{comment .}}
+//
+{{- end}}
+{{- if .Params}}
+// The parameters are attached to the error as fields. Options among opts are applied last.
+{{- else}}
+// Options among opts are applied to the error.
+{{- end}}
+func New{{.Name}}({{range .Params}}{{.Name}} {{.Type}}, {{end}}opts ...errors.Option) error {
+	args := []interface{}{ {{- range .Params}}{{.Name}}, {{end}}
+	{{- range .Params}}errors.Field({{printf "%q" .Name}}, {{.Name}}), {{end}}errors.SkipFrames(1)}
+	for _, opt := range opts {
+		args = append(args, opt)
+	}
+	return errors.NewWithCode(Code{{.Name}}, {{format .}}, args...)
+}
+{{end}}
+var httpStatus = map[errors.ErrorCode]int{
+{{- range .Errors}}{{if .HTTP}}
+	Code{{.Name}}: {{.HTTP}},
+{{- end}}{{end}}
+}
+
+// HTTPStatus returns the HTTP status of the outermost code of err's chain declared by
+// the catalog, 500 if there is none.
+func HTTPStatus(err error) int {
+	if status, ok := httpStatus[errors.ErrorCode(errors.Code(err))]; ok {
+		return status
+	}
+	return 500
+}
+
+var grpcCode = map[errors.ErrorCode]uint32{
+{{- range .Errors}}{{if .GRPC}}
+	Code{{.Name}}: {{grpc .GRPC}}, // {{.GRPC}}
+{{- end}}{{end}}
+}
+
+// GRPCCode returns the gRPC status code, as value of google.golang.org/grpc/codes.Code,
+// of the outermost code of err's chain declared by the catalog, 2 (Unknown) if there is none.
+func GRPCCode(err error) uint32 {
+	if code, ok := grpcCode[errors.ErrorCode(errors.Code(err))]; ok {
+		return code
+	}
+	return 2
+}
+`))
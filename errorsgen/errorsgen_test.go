@@ -0,0 +1,116 @@
+package errorsgen
+
+import (
+	"flag"
+	"os"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "update the generated files of the example")
+
+// TestGolden checks the code and documentation committed for the example catalog are
+// up to date.
+func TestGolden(t *testing.T) {
+
+	f, err := os.Open("internal/example/catalog.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	c, err := Parse(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	code, err := Generate(c, "catalog.yaml", "example")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, got := range map[string][]byte{
+		"internal/example/catalog_gen.go": code,
+		"internal/example/ERRORS.md":      Markdown(c),
+	} {
+		if *update {
+			if err := os.WriteFile(name, got, 0o644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		want, err := os.ReadFile(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != string(want) {
+			t.Errorf("%s is outdated, run go generate:\n%s", name, got)
+		}
+	}
+}
+
+func TestJSON(t *testing.T) {
+
+	f, err := os.Open("testdata/catalog.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	c, err := Parse(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	code, err := Generate(c, "catalog.json", "ignored")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"// Code generated by errorsgen from catalog.json. DO NOT EDIT.",
+		"package orders\n",
+		"func NewOrderNotFound(id int, store string, opts ...errors.Option) error {",
+		`errors.NewWithCode(CodeOrderNotFound, "order %d not found in store %s", args...)`,
+		"CodeOrderNotFound: 404,",
+	} {
+		if !strings.Contains(string(code), want) {
+			t.Errorf("generated code lacks %q:\n%s", want, code)
+		}
+	}
+	if strings.Contains(string(code), `"time"`) {
+		t.Errorf("generated code imports package time needlessly")
+	}
+}
+
+func TestValidate(t *testing.T) {
+
+	tests := []struct {
+		catalog string
+		err     string
+	}{
+		{"package: 1x", "invalid package name"},
+		{"errors: [{name: lower, code: 1, message: m}]", "not an exported Go identifier"},
+		{"errors: [{name: A, code: 1, message: m}, {name: A, code: 2, message: m}]", "declared twice"},
+		{"errors: [{name: A, code: 1, message: m}, {name: B, code: 1, message: m}]", "code 1 is the code of A already"},
+		{"errors: [{name: A, code: 0, message: m}]", "out of range"},
+		{"errors: [{name: A, code: 1, http: 999, message: m}]", "invalid HTTP status"},
+		{"errors: [{name: A, code: 1, grpc: Lost, message: m}]", `unknown gRPC code "Lost"`},
+		{"errors: [{name: A, code: 1, severity: fatal, message: m}]", `unknown severity "fatal"`},
+		{"errors: [{name: A, code: 1, retry: maybe, message: m}]", `unknown retryability "maybe"`},
+		{"errors: [{name: A, code: 1}]", "message missing"},
+		{"errors: [{name: A, code: 1, message: '{x}'}]", "unknown parameter {x}"},
+		{"errors: [{name: A, code: 1, message: m, params: [{name: x, type: int}]}]", "parameter x is not used"},
+		{"errors: [{name: A, code: 1, message: '{x}', params: [{name: x, type: chan}]}]", `unsupported type "chan"`},
+		{"errors: [{name: A, code: 1, message: '{x}', params: [{name: x, type: int}, {name: x, type: int}]}]", "parameter x declared twice"},
+		{"errors: [{name: A, code: 1, message: m, status: 1}]", "field status not found"},
+		{"errors: [{name: A, code: 1, message: '{args}', params: [{name: args, type: int}]}]", "parameter name args is reserved"},
+		{"errors: [{name: A, code: 1, message: '{opt}', params: [{name: opt, type: int}]}]", "parameter name opt is reserved"},
+		{"errors: [{name: A, code: 1, message: '{opts}', params: [{name: opts, type: int}]}]", "parameter name opts is reserved"},
+		{"errors: [{name: A, code: 1, message: '{errors}', params: [{name: errors, type: string}]}]", "parameter name errors is reserved"},
+		{"errors: [{name: A, code: 1, message: '{time}', params: [{name: time, type: time.Time}]}]", "parameter name time is reserved"},
+		{"errors: [{name: A, code: 1, message: '{string}', params: [{name: string, type: string}]}]", "parameter name string is reserved"},
+		{"errors: [{name: A, code: 1, message: '{CodeA}', params: [{name: CodeA, type: int}]}]", "parameter name CodeA is reserved"},
+	}
+
+	for _, tt := range tests {
+		_, err := Parse(strings.NewReader(tt.catalog))
+		if err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("Parse(%q) = %v, want error containing %q", tt.catalog, err, tt.err)
+		}
+	}
+}
@@ -0,0 +1,8 @@
+module github.com/ihleven/errors/errorsgen
+
+go 1.25.0
+
+require (
+	github.com/ihleven/errors v0.2.0
+	gopkg.in/yaml.v3 v3.0.1
+)
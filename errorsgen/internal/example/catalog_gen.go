@@ -0,0 +1,114 @@
+// Code generated by errorsgen from catalog.yaml. DO NOT EDIT.
+
+package example
+
+import (
+	"time"
+
+	"github.com/ihleven/errors"
+)
+
+// Codes of the errors of the catalog.
+const (
+	// CodeOrderNotFound is the code of the errors returned by NewOrderNotFound.
+	CodeOrderNotFound errors.ErrorCode = 4041
+	// CodeQuotaExceeded is the code of the errors returned by NewQuotaExceeded.
+	CodeQuotaExceeded errors.ErrorCode = 4291
+	// CodeStorageFailed is the code of the errors returned by NewStorageFailed.
+	CodeStorageFailed errors.ErrorCode = 5031
+	// CodeDiskFull is the code of the errors returned by NewDiskFull.
+	CodeDiskFull errors.ErrorCode = 5071
+)
+
+func init() {
+	errors.RegisterCode(CodeOrderNotFound, errors.CodeInfo{Name: "OrderNotFound", Category: "client", Severity: errors.SeverityDebug, Retry: errors.RetryPermanent})
+	errors.RegisterCode(CodeQuotaExceeded, errors.CodeInfo{Name: "QuotaExceeded", Category: "client", Severity: errors.SeverityInfo, Retry: errors.RetryTemporary})
+	errors.RegisterCode(CodeStorageFailed, errors.CodeInfo{Name: "StorageFailed", Category: "dependency", Severity: errors.SeverityCritical})
+	errors.RegisterCode(CodeDiskFull, errors.CodeInfo{Name: "DiskFull", Category: "server", Severity: errors.SeverityCritical})
+}
+
+// NewOrderNotFound returns an error with the code CodeOrderNotFound and the message
+//
+//	order {id} not found in store {store}
+//
+// The order does not exist or was deleted.
+//
+// The parameters are attached to the error as fields. Options among opts are applied last.
+func NewOrderNotFound(id int, store string, opts ...errors.Option) error {
+	args := []interface{}{id, store, errors.Field("id", id), errors.Field("store", store), errors.SkipFrames(1)}
+	for _, opt := range opts {
+		args = append(args, opt)
+	}
+	return errors.NewWithCode(CodeOrderNotFound, "order %d not found in store %s", args...)
+}
+
+// NewQuotaExceeded returns an error with the code CodeQuotaExceeded and the message
+//
+//	quota of {limit} requests exceeded, used 100%, retry in {wait}
+//
+// The caller sent too many requests.
+// Retry after the duration given.
+//
+// The parameters are attached to the error as fields. Options among opts are applied last.
+func NewQuotaExceeded(limit int64, wait time.Duration, opts ...errors.Option) error {
+	args := []interface{}{limit, wait, errors.Field("limit", limit), errors.Field("wait", wait), errors.SkipFrames(1)}
+	for _, opt := range opts {
+		args = append(args, opt)
+	}
+	return errors.NewWithCode(CodeQuotaExceeded, "quota of %d requests exceeded, used 100%%, retry in %s", args...)
+}
+
+// NewStorageFailed returns an error with the code CodeStorageFailed and the message
+//
+//	storage failed
+//
+// Options among opts are applied to the error.
+func NewStorageFailed(opts ...errors.Option) error {
+	args := []interface{}{errors.SkipFrames(1)}
+	for _, opt := range opts {
+		args = append(args, opt)
+	}
+	return errors.NewWithCode(CodeStorageFailed, "storage failed", args...)
+}
+
+// NewDiskFull returns an error with the code CodeDiskFull and the message
+//
+//	disk 100% full
+//
+// Options among opts are applied to the error.
+func NewDiskFull(opts ...errors.Option) error {
+	args := []interface{}{errors.SkipFrames(1)}
+	for _, opt := range opts {
+		args = append(args, opt)
+	}
+	return errors.NewWithCode(CodeDiskFull, "disk 100%% full", args...)
+}
+
+var httpStatus = map[errors.ErrorCode]int{
+	CodeOrderNotFound: 404,
+	CodeQuotaExceeded: 429,
+}
+
+// HTTPStatus returns the HTTP status of the outermost code of err's chain declared by
+// the catalog, 500 if there is none.
+func HTTPStatus(err error) int {
+	if status, ok := httpStatus[errors.ErrorCode(errors.Code(err))]; ok {
+		return status
+	}
+	return 500
+}
+
+var grpcCode = map[errors.ErrorCode]uint32{
+	CodeOrderNotFound: 5,  // NotFound
+	CodeQuotaExceeded: 8,  // ResourceExhausted
+	CodeStorageFailed: 14, // Unavailable
+}
+
+// GRPCCode returns the gRPC status code, as value of google.golang.org/grpc/codes.Code,
+// of the outermost code of err's chain declared by the catalog, 2 (Unknown) if there is none.
+func GRPCCode(err error) uint32 {
+	if code, ok := grpcCode[errors.ErrorCode(errors.Code(err))]; ok {
+		return code
+	}
+	return 2
+}
@@ -0,0 +1,4 @@
+// Package example demonstrates errorsgen. Its errors are declared in catalog.yaml.
+package example
+
+//go:generate go run github.com/ihleven/errors/errorsgen/cmd/errorsgen -md ERRORS.md catalog.yaml
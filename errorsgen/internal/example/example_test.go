@@ -0,0 +1,64 @@
+package example
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ihleven/errors"
+)
+
+func TestGenerated(t *testing.T) {
+
+	err := NewOrderNotFound(42, "berlin", errors.Public("order not found"))
+	if got, want := err.Error(), "order 42 not found in store berlin"; got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+	if !errors.HasCode(err, CodeOrderNotFound) {
+		t.Errorf("error lacks code %d", CodeOrderNotFound)
+	}
+	if fields := errors.Fields(err); fields["id"] != 42 || fields["store"] != "berlin" {
+		t.Errorf("Fields = %v", fields)
+	}
+	if got := errors.PublicMessage(err); got != "order not found" {
+		t.Errorf("PublicMessage = %q", got)
+	}
+	if got := errors.GetSeverity(err); got != errors.SeverityDebug {
+		t.Errorf("GetSeverity = %v, want the registered SeverityDebug", got)
+	}
+	if errors.IsRetryable(err) {
+		t.Errorf("IsRetryable = true for a permanent error")
+	}
+	if got := errors.Layers(err)[0].Function; got != "TestGenerated" && errors.GetStackTrace(err) != nil {
+		t.Errorf("error created in %s, want the caller of NewOrderNotFound", got)
+	}
+
+	wrapped := errors.Wrap(NewQuotaExceeded(100, time.Second), "calling")
+	if got, want := wrapped.Error(), "calling: quota of 100 requests exceeded, used 100%, retry in 1s"; got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+	if !errors.IsRetryable(wrapped) {
+		t.Errorf("IsRetryable = false for a temporary error")
+	}
+
+	if got, want := NewDiskFull().Error(), "disk 100% full"; got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+
+	for _, tt := range []struct {
+		err  error
+		http int
+		grpc uint32
+	}{
+		{err, 404, 5},
+		{wrapped, 429, 8},
+		{NewStorageFailed(), 500, 14},
+		{errors.New("other"), 500, 2},
+	} {
+		if got := HTTPStatus(tt.err); got != tt.http {
+			t.Errorf("HTTPStatus(%v) = %d, want %d", tt.err, got, tt.http)
+		}
+		if got := GRPCCode(tt.err); got != tt.grpc {
+			t.Errorf("GRPCCode(%v) = %d, want %d", tt.err, got, tt.grpc)
+		}
+	}
+}
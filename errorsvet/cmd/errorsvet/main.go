@@ -0,0 +1,14 @@
+// Command errorsvet runs the analyzers of package errorsvet, standalone or through go vet:
+//
+//	go vet -vettool=$(which errorsvet) ./...
+package main
+
+import (
+	"golang.org/x/tools/go/analysis/multichecker"
+
+	"github.com/ihleven/errors/errorsvet"
+)
+
+func main() {
+	multichecker.Main(errorsvet.Analyzer, errorsvet.WrapAnalyzer)
+}
@@ -0,0 +1,264 @@
+// Package errorsvet provides analyzers reporting misuse of package github.com/ihleven/errors.
+//
+// Analyzer checks calls of New, NewWithCode, NewCtx, Wrap and WrapCtx: their format
+// strings are checked against the number of arguments the way go vet checks fmt.Printf,
+// leaving out the Options among the arguments. Wrap ignores all of its arguments if the
+// first one is not a string, which is reported, as are calls wrapping an error which is
+// nil for sure and thus return nil.
+//
+// WrapAnalyzer reports exported functions returning errors of other packages as they are,
+// without a call of Wrap recording where the error left the package. Exemptions are given
+// with the flag -errorswrap.allow.
+//
+// The analyzers can be run by go vet through the command errorsvet:
+//
+//	go install github.com/ihleven/errors/errorsvet/cmd/errorsvet@latest
+//	go vet -vettool=$(which errorsvet) ./...
+package errorsvet
+
+import (
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+// errorsPath is the import path of the package whose calls are checked.
+const errorsPath = "github.com/ihleven/errors"
+
+// Analyzer reports misuse of New, NewWithCode, NewCtx, Wrap and WrapCtx.
+var Analyzer = &analysis.Analyzer{
+	Name:     "errorsvet",
+	Doc:      "check calls of github.com/ihleven/errors constructors for format mismatches, ignored arguments and nil errors",
+	URL:      "https://pkg.go.dev/github.com/ihleven/errors/errorsvet",
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Run:      run,
+}
+
+func run(pass *analysis.Pass) (interface{}, error) {
+
+	inspect := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+
+	inspect.WithStack([]ast.Node{(*ast.CallExpr)(nil)}, func(n ast.Node, push bool, stack []ast.Node) bool {
+		if !push {
+			return true
+		}
+		call := n.(*ast.CallExpr)
+		fn, ok := typeutil.Callee(pass.TypesInfo, call).(*types.Func)
+		if !ok || fn.Pkg() == nil || fn.Pkg().Path() != errorsPath {
+			return true
+		}
+
+		switch fn.Name() {
+		case "New":
+			checkFormat(pass, call, fn.Name(), 0)
+		case "NewWithCode", "NewCtx":
+			checkFormat(pass, call, fn.Name(), 1)
+		case "Wrap":
+			checkWrap(pass, call, fn.Name(), 0, stack)
+		case "WrapCtx":
+			checkWrap(pass, call, fn.Name(), 1, stack)
+		}
+		return true
+	})
+	return nil, nil
+}
+
+// checkFormat checks the format string at index format of call and the arguments following it.
+func checkFormat(pass *analysis.Pass, call *ast.CallExpr, name string, format int) {
+	if len(call.Args) > format {
+		checkArgs(pass, call, name, call.Args[format], values(pass, call.Args[format+1:]))
+	}
+}
+
+// checkArgs checks the format string against the values formatted.
+func checkArgs(pass *analysis.Pass, call *ast.CallExpr, name string, format ast.Expr, values []ast.Expr) {
+
+	if call.Ellipsis.IsValid() {
+		// the arguments are not known
+		return
+	}
+
+	tv := pass.TypesInfo.Types[format]
+	if tv.Value == nil || tv.Value.Kind() != constant.String {
+		if len(values) == 0 {
+			pass.Report(analysis.Diagnostic{
+				Pos:     format.Pos(),
+				End:     format.End(),
+				Message: fmt.Sprintf("non-constant format string in call to %s", name),
+				SuggestedFixes: []analysis.SuggestedFix{{
+					Message:   `Insert "%s" format string`,
+					TextEdits: []analysis.TextEdit{{Pos: format.Pos(), End: format.Pos(), NewText: []byte(`"%s", `)}},
+				}},
+			})
+		} else if isSprintf(pass, format) {
+			pass.Reportf(format.Pos(), "%s formats the result of fmt.Sprintf with further arguments", name)
+		}
+		return
+	}
+
+	text := constant.StringVal(tv.Value)
+	if n, ok := formatArgs(text); ok && n != len(values) {
+		pass.Reportf(call.Pos(), "%s format %q reads %s, but call has %s", name, text, count(n), count(len(values)))
+	}
+}
+
+// checkWrap checks the arguments of Wrap or WrapCtx, err being the index of the error wrapped.
+func checkWrap(pass *analysis.Pass, call *ast.CallExpr, name string, err int, stack []ast.Node) {
+
+	if len(call.Args) <= err {
+		return
+	}
+	checkNil(pass, call, name, call.Args[err], stack)
+
+	args := values(pass, call.Args[err+1:])
+	if len(args) == 0 || call.Ellipsis.IsValid() {
+		return
+	}
+	if !isString(pass.TypesInfo.TypeOf(args[0])) {
+		pass.Reportf(args[0].Pos(), "%s ignores its arguments, as the first one is of type %s and not a format string",
+			name, pass.TypesInfo.TypeOf(args[0]))
+		return
+	}
+	checkArgs(pass, call, name, args[0], args[1:])
+}
+
+// checkNil reports wrapping an error which is nil for sure, i.e. the literal nil or a
+// variable compared to nil by an enclosing if statement.
+func checkNil(pass *analysis.Pass, call *ast.CallExpr, name string, err ast.Expr, stack []ast.Node) {
+
+	if pass.TypesInfo.Types[err].IsNil() {
+		pass.Reportf(err.Pos(), "%s of nil always returns nil", name)
+		return
+	}
+
+	id, ok := ast.Unparen(err).(*ast.Ident)
+	if !ok {
+		return
+	}
+	obj := pass.TypesInfo.Uses[id]
+	if obj == nil {
+		return
+	}
+
+	for i := len(stack) - 2; i >= 0; i-- {
+		switch n := stack[i].(type) {
+		case *ast.FuncLit, *ast.FuncDecl:
+			return
+		case *ast.IfStmt:
+			op, ok := nilComparison(pass, n.Cond, obj)
+			if !ok {
+				continue
+			}
+			if (stack[i+1] == n.Body && op == token.EQL) || (stack[i+1] == n.Else && op == token.NEQ) {
+				pass.Reportf(err.Pos(), "%s of %s, which is always nil here, returns nil", name, id.Name)
+				return
+			}
+		}
+	}
+}
+
+// nilComparison returns the operator of cond if it compares obj to nil.
+func nilComparison(pass *analysis.Pass, cond ast.Expr, obj types.Object) (token.Token, bool) {
+
+	bin, ok := ast.Unparen(cond).(*ast.BinaryExpr)
+	if !ok || (bin.Op != token.EQL && bin.Op != token.NEQ) {
+		return 0, false
+	}
+	x, y := ast.Unparen(bin.X), ast.Unparen(bin.Y)
+	if pass.TypesInfo.Types[x].IsNil() {
+		x, y = y, x
+	}
+	id, ok := x.(*ast.Ident)
+	if !ok || pass.TypesInfo.Uses[id] != obj || !pass.TypesInfo.Types[y].IsNil() {
+		return 0, false
+	}
+	return bin.Op, true
+}
+
+// isSprintf reports whether expr is a call of fmt.Sprintf.
+func isSprintf(pass *analysis.Pass, expr ast.Expr) bool {
+	call, ok := ast.Unparen(expr).(*ast.CallExpr)
+	if !ok {
+		return false
+	}
+	fn, ok := typeutil.Callee(pass.TypesInfo, call).(*types.Func)
+	return ok && fn.Pkg() != nil && fn.Pkg().Path() == "fmt" && fn.Name() == "Sprintf"
+}
+
+// values returns the arguments which are not Options.
+func values(pass *analysis.Pass, args []ast.Expr) []ast.Expr {
+	var vals []ast.Expr
+	for _, arg := range args {
+		if !isOption(pass.TypesInfo.TypeOf(arg)) {
+			vals = append(vals, arg)
+		}
+	}
+	return vals
+}
+
+func isOption(t types.Type) bool {
+	named, ok := t.(*types.Named)
+	if !ok {
+		return false
+	}
+	obj := named.Obj()
+	return obj.Pkg() != nil && obj.Pkg().Path() == errorsPath && obj.Name() == "Option"
+}
+
+func isString(t types.Type) bool {
+	basic, ok := t.Underlying().(*types.Basic)
+	return ok && basic.Info()&types.IsString != 0
+}
+
+// formatArgs returns the number of arguments format reads, false if that cannot be told
+// because format uses explicit argument indexes.
+func formatArgs(format string) (int, bool) {
+
+	n := 0
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			continue
+		}
+		i++
+		for i < len(format) && strings.IndexByte("+-# 0", format[i]) >= 0 {
+			i++
+		}
+		if i < len(format) && format[i] == '[' {
+			return 0, false
+		}
+		i, n = skipNumber(format, i, n)
+		if i < len(format) && format[i] == '.' {
+			i, n = skipNumber(format, i+1, n)
+		}
+		if i < len(format) && format[i] != '%' {
+			n++
+		}
+	}
+	return n, true
+}
+
+// skipNumber skips a width or precision starting at i, counting the argument read by '*'.
+func skipNumber(format string, i, n int) (int, int) {
+	if i < len(format) && format[i] == '*' {
+		return i + 1, n + 1
+	}
+	for i < len(format) && format[i] >= '0' && format[i] <= '9' {
+		i++
+	}
+	return i, n
+}
+
+func count(n int) string {
+	if n == 1 {
+		return "1 arg"
+	}
+	return fmt.Sprintf("%d args", n)
+}
@@ -0,0 +1,20 @@
+package errorsvet_test
+
+import (
+	"testing"
+
+	"golang.org/x/tools/go/analysis/analysistest"
+
+	"github.com/ihleven/errors/errorsvet"
+)
+
+func TestAnalyzer(t *testing.T) {
+	analysistest.RunWithSuggestedFixes(t, analysistest.TestData(), errorsvet.Analyzer, "a")
+}
+
+func TestWrapAnalyzer(t *testing.T) {
+	errorsvet.WrapAnalyzer.Flags.Set("allow", "io.EOF,fmt.Errorf,dep.Allowed")
+	defer errorsvet.WrapAnalyzer.Flags.Set("allow", errorsvet.WrapAnalyzer.Flags.Lookup("allow").DefValue)
+
+	analysistest.RunWithSuggestedFixes(t, analysistest.TestData(), errorsvet.WrapAnalyzer, "b", "c")
+}
@@ -0,0 +1,56 @@
+package a
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ihleven/errors"
+)
+
+func formats(ctx context.Context, input string, id int) {
+	_ = errors.New("no user %d", id)
+	_ = errors.New("no user %d in %s", id, input, errors.WithCode(errors.NotFound), errors.Field("id", id))
+	_ = errors.New("100%% done, %*d", 3, id)
+	_ = errors.New("%[1]d %[1]d", id)
+	_ = errors.New(input)                                // want `non-constant format string in call to New`
+	_ = errors.New("no user %d")                         // want `New format "no user %d" reads 1 arg, but call has 0 args`
+	_ = errors.New("no user", id)                        // want `New format "no user" reads 0 args, but call has 1 arg`
+	_ = errors.NewWithCode(errors.NotFound, "%s %d", id) // want `NewWithCode format "%s %d" reads 2 args, but call has 1 arg`
+	_ = errors.NewCtx(ctx, fmt.Sprintf("user %d", id))   // want `non-constant format string in call to NewCtx`
+
+	args := []interface{}{id}
+	_ = errors.New("no user %d %d", args...)
+}
+
+func wraps(ctx context.Context, err error, id int) error {
+	_ = errors.Wrap(err)
+	_ = errors.Wrap(err, errors.WithCode(errors.NotFound))
+	_ = errors.Wrap(err, "loading user %d", id, errors.Field("id", id))
+	_ = errors.Wrap(err, fmt.Sprintf("loading user %d", id))     // want `non-constant format string in call to Wrap`
+	_ = errors.Wrap(err, fmt.Sprintf("loading user %d", id), id) // want `Wrap formats the result of fmt.Sprintf with further arguments`
+	_ = errors.Wrap(err, "loading user %d")                      // want `Wrap format "loading user %d" reads 1 arg, but call has 0 args`
+	_ = errors.Wrap(err, id, "loading user")                     // want `Wrap ignores its arguments, as the first one is of type int and not a format string`
+	_ = errors.WrapCtx(ctx, err, "loading", id)                  // want `WrapCtx format "loading" reads 0 args, but call has 1 arg`
+	_ = errors.Wrap(nil, "loading")                              // want `Wrap of nil always returns nil`
+
+	if err == nil {
+		return errors.Wrap(err, "loading") // want `Wrap of err, which is always nil here, returns nil`
+	}
+	if err != nil {
+		return errors.Wrap(err, "loading")
+	} else {
+		return errors.WrapCtx(ctx, err, "loading") // want `WrapCtx of err, which is always nil here, returns nil`
+	}
+}
+
+func closures(err error) {
+	if err == nil {
+		func(err error) {
+			_ = errors.Wrap(err)
+		}(fmt.Errorf("other"))
+	}
+}
+
+func forwards(err error, args ...interface{}) error {
+	return errors.Wrap(err, args...)
+}
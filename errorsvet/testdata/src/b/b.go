@@ -0,0 +1,88 @@
+package b
+
+import (
+	"fmt"
+	"io"
+
+	"dep"
+
+	"github.com/ihleven/errors"
+)
+
+func Load(name string) ([]byte, error) {
+	data, err := dep.Load(name)
+	if err != nil {
+		return nil, err // want `error of dep.Load returned unwrapped by Load`
+	}
+	return data, nil
+}
+
+func Get(c *dep.Client, key string) (string, error) {
+	value, err := c.Get(key)
+	if err != nil {
+		return "", err // want `error of \(\*dep.Client\).Get returned unwrapped by Get`
+	}
+	return value, nil
+}
+
+func Check() error {
+	return dep.Check() // want `error of dep.Check returned unwrapped by Check`
+}
+
+func Gone() (int, error) {
+	return 0, dep.ErrGone // want `error of dep.ErrGone returned unwrapped by Gone`
+}
+
+func Forward(name string) ([]byte, error) {
+	return dep.Load(name) // want `error of dep.Load returned unwrapped`
+}
+
+func Wrapped(name string) ([]byte, error) {
+	data, err := dep.Load(name)
+	if err != nil {
+		return nil, errors.Wrap(err, "loading %s", name)
+	}
+	return data, nil
+}
+
+func Reassigned(name string) error {
+	_, err := dep.Load(name)
+	if err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+	return nil
+}
+
+func Exempt(r io.Reader, p []byte) (int, error) {
+	if err := dep.Allowed(); err != nil {
+		return 0, err
+	}
+	if n, err := r.Read(p); err != nil {
+		return n, fmt.Errorf("reading: %w", err)
+	}
+	return 0, io.EOF
+}
+
+func local() error { return nil }
+
+func Local() error {
+	if err := local(); err != nil {
+		return err
+	}
+	return check()
+}
+
+func check() error {
+	return dep.Check()
+}
+
+func Param(err error) error {
+	return err
+}
+
+func Closure() func() error {
+	return func() error {
+		return dep.Check()
+	}
+}
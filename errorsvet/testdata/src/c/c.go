@@ -0,0 +1,8 @@
+package c
+
+import "dep"
+
+func Check() error {
+	err := dep.Check()
+	return err // want `error of dep.Check returned unwrapped by Check`
+}
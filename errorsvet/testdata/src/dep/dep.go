@@ -0,0 +1,16 @@
+// Package dep is a dependency returning errors for the tests of WrapAnalyzer.
+package dep
+
+import "errors"
+
+var ErrGone = errors.New("gone")
+
+type Client struct{}
+
+func (c *Client) Get(key string) (string, error) { return "", ErrGone }
+
+func Load(name string) ([]byte, error) { return nil, ErrGone }
+
+func Check() error { return nil }
+
+func Allowed() error { return nil }
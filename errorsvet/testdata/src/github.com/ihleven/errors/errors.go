@@ -0,0 +1,19 @@
+// Package errors is a stub of github.com/ihleven/errors for the tests of the analyzers.
+package errors
+
+import "context"
+
+type ErrorCode int
+
+const NotFound ErrorCode = 404
+
+type Option func()
+
+func WithCode(code ErrorCode) Option             { return nil }
+func Field(key string, value interface{}) Option { return nil }
+
+func New(format string, args ...interface{}) error                         { return nil }
+func NewWithCode(code ErrorCode, format string, args ...interface{}) error { return nil }
+func NewCtx(ctx context.Context, format string, args ...interface{}) error { return nil }
+func Wrap(err error, args ...interface{}) error                            { return nil }
+func WrapCtx(ctx context.Context, err error, args ...interface{}) error    { return nil }
@@ -0,0 +1,294 @@
+package errorsvet
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/token"
+	"go/types"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+// WrapAnalyzer reports exported functions returning errors received from other packages
+// without passing them through Wrap, so that every error leaving a package carries the
+// location it left at. Functions, variables and packages given by the flag -allow are
+// exempt. A fix inserting the call of Wrap is suggested.
+var WrapAnalyzer = &analysis.Analyzer{
+	Name:     "errorswrap",
+	Doc:      "report errors of other packages returned by exported functions without errors.Wrap",
+	URL:      "https://pkg.go.dev/github.com/ihleven/errors/errorsvet",
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Run:      runWrap,
+}
+
+// allow lists the functions, variables and packages whose errors may be returned unwrapped,
+// as import path, optionally followed by a dot and the name of the function or variable,
+// or the type and method name for methods, like "database/sql.DB.Query".
+// io.EOF has to be returned unwrapped by readers, fmt.Errorf and the standard errors
+// package create errors or take chains apart rather than passing errors on.
+var allow = "io.EOF,fmt.Errorf,errors"
+
+func init() {
+	WrapAnalyzer.Flags.StringVar(&allow, "allow", allow, "comma separated functions, variables and packages whose errors may be returned unwrapped")
+}
+
+// allowed reports whether errors of obj may be returned unwrapped.
+func allowed(obj types.Object) bool {
+	path := obj.Pkg().Path()
+	if path == errorsPath || strings.HasPrefix(path, errorsPath+"/") {
+		return true
+	}
+	name := path + "." + obj.Name()
+	if fn, ok := obj.(*types.Func); ok {
+		if recv := fn.Type().(*types.Signature).Recv(); recv != nil {
+			// methods are listed as path.Type.Method
+			t := recv.Type()
+			if p, ok := t.(*types.Pointer); ok {
+				t = p.Elem()
+			}
+			if named, ok := t.(*types.Named); ok {
+				name = path + "." + named.Obj().Name() + "." + obj.Name()
+			}
+		}
+	}
+	for _, a := range strings.Split(allow, ",") {
+		if a = strings.TrimSpace(a); a != "" && (a == path || a == name) {
+			return true
+		}
+	}
+	return false
+}
+
+// origin returns the function or variable of another package expr obtains an error from,
+// nil if there is none or it is allowed.
+func origin(pass *analysis.Pass, expr ast.Expr) types.Object {
+
+	var obj types.Object
+	switch e := ast.Unparen(expr).(type) {
+	case *ast.CallExpr:
+		fn, ok := typeutil.Callee(pass.TypesInfo, e).(*types.Func)
+		if !ok {
+			return nil
+		}
+		obj = fn
+	case *ast.SelectorExpr:
+		v, ok := pass.TypesInfo.Uses[e.Sel].(*types.Var)
+		if !ok || v.Parent() != v.Pkg().Scope() {
+			return nil
+		}
+		obj = v
+	default:
+		return nil
+	}
+	if obj.Pkg() == nil || obj.Pkg() == pass.Pkg || allowed(obj) {
+		return nil
+	}
+	return obj
+}
+
+// assignment is the assignment of a variable, from an error of another package if origin is set.
+type assignment struct {
+	pos    token.Pos
+	origin types.Object
+}
+
+func runWrap(pass *analysis.Pass) (interface{}, error) {
+
+	inspect := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+
+	inspect.Preorder([]ast.Node{(*ast.FuncDecl)(nil)}, func(n ast.Node) {
+		decl := n.(*ast.FuncDecl)
+		if !decl.Name.IsExported() || decl.Body == nil {
+			return
+		}
+		sig := pass.TypesInfo.Defs[decl.Name].Type().(*types.Signature)
+		var errs []int
+		for i := 0; i < sig.Results().Len(); i++ {
+			if types.Identical(sig.Results().At(i).Type(), types.Universe.Lookup("error").Type()) {
+				errs = append(errs, i)
+			}
+		}
+		if len(errs) == 0 {
+			return
+		}
+
+		assigned := assignments(pass, decl.Body)
+		ast.Inspect(decl.Body, func(n ast.Node) bool {
+			switch n := n.(type) {
+			case *ast.FuncLit:
+				return false
+			case *ast.ReturnStmt:
+				if len(n.Results) == 1 && sig.Results().Len() > 1 {
+					// return f(), the results of another call
+					if obj := origin(pass, n.Results[0]); obj != nil {
+						pass.Reportf(n.Results[0].Pos(), "error of %s returned unwrapped", objName(obj))
+					}
+					return true
+				}
+				if len(n.Results) != sig.Results().Len() {
+					return true
+				}
+				for _, i := range errs {
+					checkReturn(pass, decl, n.Results[i], assigned)
+				}
+			}
+			return true
+		})
+	})
+	return nil, nil
+}
+
+// checkReturn reports the error returned by expr if it was received from another package.
+func checkReturn(pass *analysis.Pass, decl *ast.FuncDecl, expr ast.Expr, assigned map[types.Object][]assignment) {
+
+	obj := origin(pass, expr)
+	if id, ok := ast.Unparen(expr).(*ast.Ident); ok && obj == nil {
+		// the last assignment of the variable preceding the return
+		var last *assignment
+		for i, a := range assigned[pass.TypesInfo.Uses[id]] {
+			if a.pos < expr.Pos() {
+				last = &assigned[pass.TypesInfo.Uses[id]][i]
+			}
+		}
+		if last != nil {
+			obj = last.origin
+		}
+	}
+	if obj == nil {
+		return
+	}
+
+	diag := analysis.Diagnostic{
+		Pos:     expr.Pos(),
+		End:     expr.End(),
+		Message: fmt.Sprintf("error of %s returned unwrapped by %s", objName(obj), decl.Name.Name),
+	}
+	if edits := wrapEdits(pass, expr, objName(obj)); edits != nil {
+		diag.SuggestedFixes = []analysis.SuggestedFix{{Message: "Wrap the error", TextEdits: edits}}
+	}
+	pass.Report(diag)
+}
+
+// assignments returns the assignments of variables in body, in source order.
+func assignments(pass *analysis.Pass, body *ast.BlockStmt) map[types.Object][]assignment {
+
+	assigned := make(map[types.Object][]assignment)
+	add := func(lhs []ast.Expr, rhs []ast.Expr, pos token.Pos) {
+		for i, l := range lhs {
+			id, ok := l.(*ast.Ident)
+			if !ok {
+				continue
+			}
+			obj := pass.TypesInfo.ObjectOf(id)
+			if obj == nil {
+				continue
+			}
+			a := assignment{pos: pos}
+			switch {
+			case len(rhs) == len(lhs):
+				a.origin = origin(pass, rhs[i])
+			case len(rhs) == 1:
+				// x, err := f()
+				a.origin = origin(pass, rhs[0])
+			}
+			assigned[obj] = append(assigned[obj], a)
+		}
+	}
+
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.FuncLit:
+			return false
+		case *ast.AssignStmt:
+			add(n.Lhs, n.Rhs, n.Pos())
+		case *ast.ValueSpec:
+			lhs := make([]ast.Expr, len(n.Names))
+			for i, name := range n.Names {
+				lhs[i] = name
+			}
+			add(lhs, n.Values, n.Pos())
+		}
+		return true
+	})
+	for _, as := range assigned {
+		sort.Slice(as, func(i, j int) bool { return as[i].pos < as[j].pos })
+	}
+	return assigned
+}
+
+// wrapEdits returns the edits wrapping expr with errors.Wrap, importing the package if
+// needed, or nil if the name errors is taken by another package in the file.
+func wrapEdits(pass *analysis.Pass, expr ast.Expr, msg string) []analysis.TextEdit {
+
+	var file *ast.File
+	for _, f := range pass.Files {
+		if f.Pos() <= expr.Pos() && expr.Pos() < f.End() {
+			file = f
+		}
+	}
+	if file == nil {
+		return nil
+	}
+
+	name := ""
+	var edits []analysis.TextEdit
+	for _, spec := range file.Imports {
+		path, _ := strconv.Unquote(spec.Path.Value)
+		switch {
+		case path == errorsPath && spec.Name == nil:
+			name = "errors"
+		case path == errorsPath:
+			name = spec.Name.Name
+		case spec.Name == nil && path == "errors", spec.Name != nil && spec.Name.Name == "errors":
+			return nil
+		}
+	}
+	if name == "" {
+		name = "errors"
+		edits = append(edits, importEdit(file))
+	}
+
+	var buf bytes.Buffer
+	format.Node(&buf, pass.Fset, expr)
+	text := fmt.Sprintf("%s.Wrap(%s, %q)", name, buf.String(), msg)
+	return append(edits, analysis.TextEdit{Pos: expr.Pos(), End: expr.End(), NewText: []byte(text)})
+}
+
+// importEdit returns the edit importing package errors into file.
+func importEdit(file *ast.File) analysis.TextEdit {
+
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.IMPORT {
+			continue
+		}
+		if gen.Rparen.IsValid() {
+			return analysis.TextEdit{Pos: gen.Rparen, End: gen.Rparen, NewText: []byte("\n\t\"" + errorsPath + "\"\n")}
+		}
+		return analysis.TextEdit{Pos: gen.End(), End: gen.End(), NewText: []byte("\nimport \"" + errorsPath + "\"")}
+	}
+	return analysis.TextEdit{Pos: file.Name.End(), End: file.Name.End(), NewText: []byte("\n\nimport \"" + errorsPath + "\"")}
+}
+
+// objName returns the name of obj qualified by its package name, like "sql.ErrNoRows"
+// or "(*sql.DB).Query".
+func objName(obj types.Object) string {
+	if fn, ok := obj.(*types.Func); ok {
+		if recv := fn.Type().(*types.Signature).Recv(); recv != nil {
+			recv := types.TypeString(recv.Type(), (*types.Package).Name)
+			if strings.HasPrefix(recv, "*") {
+				recv = "(" + recv + ")"
+			}
+			return recv + "." + fn.Name()
+		}
+	}
+	return obj.Pkg().Name() + "." + obj.Name()
+}
@@ -1,3 +1,5 @@
 module github.com/ihleven/errors
 
-go 1.14
+go 1.21
+
+require github.com/pkg/errors v0.9.1
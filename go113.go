@@ -4,24 +4,48 @@ package errors
 
 import (
 	stderrors "errors"
+	"reflect"
 )
 
 // Is reports whether any error in err's chain matches target.
 //
-// The chain consists of err itself followed by the sequence of errors obtained by
-// repeatedly calling Unwrap.
+// The chain consists of err itself followed by the errors visited by Walk, i.e.
+// errors obtained by repeatedly calling Cause or Unwrap.
 //
 // An error is considered to match a target if it is equal to that target or if
 // it implements a method Is(error) bool such that Is(target) returns true.
-func Is(err, target error) bool { 
-	return stderrors.Is(err, target) 
+//
+// If target is an ErrorCode, Is reports whether any error in err's chain carries
+// that code (see HasCode).
+func Is(err, target error) bool {
+
+	if err == nil || target == nil {
+		return err == target
+	}
+
+	if code, ok := target.(ErrorCode); ok && HasCode(err, code) {
+		return true
+	}
+
+	comparable := hashable(target)
+	found := false
+
+	Walk(err, func(e error) bool {
+		if comparable && hashable(e) && e == target {
+			found = true
+		} else if x, ok := e.(interface{ Is(error) bool }); ok && x.Is(target) {
+			found = true
+		}
+		return !found
+	})
+	return found
 }
 
 // As finds the first error in err's chain that matches target, and if so, sets
 // target to that error value and returns true.
 //
-// The chain consists of err itself followed by the sequence of errors obtained by
-// repeatedly calling Unwrap.
+// The chain consists of err itself followed by the errors visited by Walk, i.e.
+// errors obtained by repeatedly calling Cause or Unwrap.
 //
 // An error matches target if the error's concrete value is assignable to the value
 // pointed to by target, or if the error has a method As(interface{}) bool such that
@@ -30,10 +54,37 @@ func Is(err, target error) bool {
 //
 // As will panic if target is not a non-nil pointer to either a type that implements
 // error, or to any interface type. As returns false if err is nil.
-func As(err error, target interface{}) bool { 
-	return stderrors.As(err, target) 
+func As(err error, target interface{}) bool {
+
+	if target == nil {
+		panic("errors: target cannot be nil")
+	}
+	val := reflect.ValueOf(target)
+	typ := val.Type()
+	if typ.Kind() != reflect.Ptr || val.IsNil() {
+		panic("errors: target must be a non-nil pointer")
+	}
+	targetType := typ.Elem()
+	if targetType.Kind() != reflect.Interface && !targetType.Implements(errorType) {
+		panic("errors: *target must be interface or implement error")
+	}
+
+	found := false
+
+	Walk(err, func(e error) bool {
+		if reflect.TypeOf(e).AssignableTo(targetType) {
+			val.Elem().Set(reflect.ValueOf(e))
+			found = true
+		} else if x, ok := e.(interface{ As(interface{}) bool }); ok && x.As(target) {
+			found = true
+		}
+		return !found
+	})
+	return found
 }
 
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
 // Unwrap returns the result of calling the Unwrap method on err, if err's
 // type contains an Unwrap method returning error.
 // Otherwise, Unwrap returns nil.
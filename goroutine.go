@@ -0,0 +1,113 @@
+package errors
+
+import (
+	"bytes"
+	"reflect"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+var goroutines struct {
+	running atomic.Int32 // number of goroutines launched with Go still running
+	sync.RWMutex
+	created map[uint64]*stack // goroutine id -> stack of the launching goroutine
+}
+
+// Go runs fn in a new goroutine, recording the stack of the calling goroutine.
+// Errors created by New, NewWithCode or Wrap inside fn carry that stack, printed as a
+// "created by" section by %+v and reported as Layer.CreatedBy, so that errors passed
+// back through channels tell who launched the goroutine. Goroutines launched with Go
+// from within fn add their own section.
+//
+// While no goroutine launched with Go is running, creating errors costs a single atomic
+// load more. Otherwise the bottom of the stack recorded tells whether the goroutine
+// creating the error was launched by Go, which costs about as much as resolving two
+// frames. Errors created within fn, and errors whose stack is not recorded completely,
+// e.g. with StackCaller or beyond the maximum depth, look up the launching stack by the
+// id of the goroutine, which costs several microseconds and an allocation.
+func Go(fn func()) {
+
+	var buf [initialDepth]uintptr
+	pcs, more := callers(0, &buf)
+	if len(pcs) == 0 {
+		go fn()
+		return
+	}
+	created := &stack{pcs: append([]uintptr(nil), pcs...), more: more}
+	created.created = created.creator()
+
+	goroutines.running.Add(1)
+	go launched(created, fn)
+}
+
+// launched runs fn in a goroutine launched by Go, which recorded the stack created.
+// It is the bottom frame of these goroutines, below which only runtime.goexit follows.
+//
+//go:noinline
+func launched(created *stack, fn func()) {
+
+	id := goid()
+	goroutines.Lock()
+	if goroutines.created == nil {
+		goroutines.created = make(map[uint64]*stack)
+	}
+	goroutines.created[id] = created
+	goroutines.Unlock()
+
+	defer func() {
+		goroutines.Lock()
+		delete(goroutines.created, id)
+		goroutines.Unlock()
+		goroutines.running.Add(-1)
+	}()
+	fn()
+}
+
+var launchedEntry = reflect.ValueOf(launched).Pointer()
+
+// creator returns the stack of the goroutine which launched the one recording s with Go,
+// if any.
+func (s stack) creator() *stack {
+
+	if goroutines.running.Load() == 0 {
+		return nil
+	}
+	if n := len(s.pcs); s.more == 0 && n >= 2 && runtime.FuncForPC(s.pcs[n-1]).Name() == "runtime.goexit" {
+		// the stack is complete, its bottom frame tells whether Go launched the goroutine
+		if f := runtime.FuncForPC(s.pcs[n-2]); f == nil || f.Entry() != launchedEntry {
+			return nil
+		}
+	}
+
+	id := goid()
+	goroutines.RLock()
+	defer goroutines.RUnlock()
+	return goroutines.created[id]
+}
+
+// createdBy returns the stacks of the goroutines which launched the recording one, innermost first.
+func (s stack) createdBy() []StackTrace {
+	var stacks []StackTrace
+	for c := s.created; c != nil; c = c.created {
+		stacks = append(stacks, c.StackTrace())
+	}
+	return stacks
+}
+
+// goid returns the id of the current goroutine, parsed from the header of its traceback
+// "goroutine 18 [running]:".
+func goid() uint64 {
+
+	var buf [64]byte
+	b := buf[:runtime.Stack(buf[:], false)]
+	b = bytes.TrimPrefix(b, []byte("goroutine "))
+	var id uint64
+	for _, c := range b {
+		if c < '0' || c > '9' {
+			break
+		}
+		id = 10*id + uint64(c-'0')
+	}
+	return id
+}
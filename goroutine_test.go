@@ -0,0 +1,71 @@
+//go:build !errors_nostack
+
+package errors_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/ihleven/errors"
+)
+
+func spawnWorker(errs chan<- error) {
+	errors.Go(func() {
+		errs <- errors.New("worker failed")
+	})
+}
+
+func TestGo(t *testing.T) {
+
+	errs := make(chan error)
+	errors.Go(func() { spawnWorker(errs) })
+	err := errors.Wrap(<-errs, "waiting for worker")
+
+	text := fmt.Sprintf("%+v", err)
+	if n := strings.Count(text, "\ncreated by\n"); n != 2 {
+		t.Errorf("%%+v has %d created by sections, want 2:\n%s", n, text)
+	}
+	if i, j := strings.Index(text, "errors_test.spawnWorker\n"), strings.Index(text, "errors_test.TestGo\n"); i < 0 || j < i {
+		t.Errorf("%%+v does not show spawnWorker launched by TestGo:\n%s", text)
+	}
+
+	layer := errors.Layers(err)[1]
+	if len(layer.CreatedBy) != 2 {
+		t.Fatalf("CreatedBy holds %d stacks, want 2", len(layer.CreatedBy))
+	}
+	if fn := fmt.Sprintf("%n", layer.CreatedBy[0][0]); fn != "spawnWorker" {
+		t.Errorf("CreatedBy[0] starts at %s, want spawnWorker", fn)
+	}
+	if fn := fmt.Sprintf("%n", layer.CreatedBy[1][0]); fn != "TestGo" {
+		t.Errorf("CreatedBy[1] starts at %s, want TestGo", fn)
+	}
+	if b, _ := json.Marshal(layer); !strings.Contains(string(b), `"created_by":[["github.com/ihleven/errors_test.spawnWorker`) {
+		t.Errorf("JSON %s lacks created_by", b)
+	}
+
+	// errors created outside of goroutines launched with Go carry no such section
+	if text := fmt.Sprintf("%+v", errors.New("plain")); strings.Contains(text, "created by") {
+		t.Errorf("%%+v of an error outside Go has a created by section:\n%s", text)
+	}
+}
+
+func TestGoCreator(t *testing.T) {
+
+	// errors created elsewhere while a goroutine launched with Go runs carry no section
+	done := make(chan struct{})
+	defer close(done)
+	errors.Go(func() { <-done })
+	if layer := errors.Layers(errors.New("elsewhere"))[0]; layer.CreatedBy != nil {
+		t.Errorf("CreatedBy = %v of an error outside Go", layer.CreatedBy)
+	}
+
+	// incomplete stacks are looked up by goroutine
+	setStackMode(t, errors.StackCaller, 1)
+	errs := make(chan error)
+	errors.Go(func() { errs <- errors.New("caller only") })
+	if layer := errors.Layers(<-errs)[0]; len(layer.CreatedBy) != 1 {
+		t.Errorf("CreatedBy holds %d stacks with StackCaller, want 1", len(layer.CreatedBy))
+	}
+}
@@ -0,0 +1,114 @@
+package errors
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// EventKind tells how an error was created.
+type EventKind int
+
+const (
+	// EventNew is the creation of an error by New, NewWithCode or NewCtx.
+	EventNew EventKind = iota
+	// EventWrap is the wrapping of an error by Wrap or WrapCtx.
+	EventWrap
+)
+
+func (k EventKind) String() string {
+	if k == EventWrap {
+		return "wrap"
+	}
+	return "new"
+}
+
+// Event describes the creation of an error, passed to hooks registered with AddHook.
+type Event struct {
+	Kind     EventKind
+	Err      error  // the error returned to the caller
+	Function string // function which created the error
+	File     string // file which created the error
+	Line     int    // line which created the error
+}
+
+// Hook observes the creation of errors. Hooks are called synchronously by New, NewWithCode
+// and Wrap, so they should be fast and must be safe for concurrent use.
+type Hook func(Event)
+
+type hookEntry struct {
+	hook Hook
+}
+
+var hooks struct {
+	sync.Mutex                              // serializes writers
+	entries    atomic.Pointer[[]*hookEntry] // read without locking
+}
+
+// AddHook registers hook to be called whenever New, NewWithCode or Wrap return an error
+// created by this package. It returns a function removing the hook again, e.g. to be
+// passed to testing.T.Cleanup. Without hooks registered, creating errors costs a single
+// atomic load more.
+func AddHook(hook Hook) (remove func()) {
+
+	entry := &hookEntry{hook}
+
+	hooks.Lock()
+	defer hooks.Unlock()
+
+	var entries []*hookEntry
+	if old := hooks.entries.Load(); old != nil {
+		entries = append(entries, *old...)
+	}
+	entries = append(entries, entry)
+	hooks.entries.Store(&entries)
+
+	var once sync.Once
+	return func() {
+		once.Do(func() { removeHook(entry) })
+	}
+}
+
+func removeHook(entry *hookEntry) {
+
+	hooks.Lock()
+	defer hooks.Unlock()
+
+	old := hooks.entries.Load()
+	if old == nil {
+		return
+	}
+	var entries []*hookEntry
+	for _, e := range *old {
+		if e != entry {
+			entries = append(entries, e)
+		}
+	}
+	if len(entries) == 0 {
+		hooks.entries.Store(nil)
+		return
+	}
+	hooks.entries.Store(&entries)
+}
+
+// observe calls the registered hooks for the creation of err.
+func observe(kind EventKind, err error) {
+
+	entries := hooks.entries.Load()
+	if entries == nil {
+		return
+	}
+
+	event := Event{Kind: kind, Err: err}
+	switch x := err.(type) {
+	case *withMessage:
+		event.File, event.Function, event.Line = location(x.frame)
+	case *fundamental:
+		event.File, event.Function, event.Line = x.stack.location()
+	case *withStack:
+		event.File, event.Function, event.Line = x.stack.location()
+	}
+
+	for _, e := range *entries {
+		e.hook(event)
+	}
+}
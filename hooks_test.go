@@ -0,0 +1,74 @@
+package errors_test
+
+import (
+	"fmt"
+	"path"
+	"sync"
+	"testing"
+
+	"github.com/ihleven/errors"
+)
+
+func TestHooks(t *testing.T) {
+
+	requireStacks(t)
+	var events []errors.Event
+	remove := errors.AddHook(func(e errors.Event) { events = append(events, e) })
+	t.Cleanup(remove)
+
+	err := errors.New("boom")
+	wrapped := errors.Wrap(err, "wrapping")
+	errors.Wrap(wrapped)
+	errors.Wrap(fmt.Errorf("foreign"))
+
+	if len(events) != 3 {
+		t.Fatalf("got %d events, want 3: %v", len(events), events)
+	}
+	want := []struct {
+		kind errors.EventKind
+		line int
+	}{
+		{errors.EventNew, 19}, {errors.EventWrap, 20}, {errors.EventWrap, 22},
+	}
+	for i, e := range events {
+		if e.Kind != want[i].kind || e.Line != want[i].line || e.Function != "TestHooks" || path.Base(e.File) != "hooks_test.go" {
+			t.Errorf("event %d = %v %s:%d (%s), want %v at line %d", i, e.Kind, e.File, e.Line, e.Function, want[i].kind, want[i].line)
+		}
+	}
+	if events[0].Err != err || events[1].Err != wrapped {
+		t.Errorf("events do not carry the created errors")
+	}
+
+	remove()
+	remove()
+	errors.New("unobserved")
+	if len(events) != 3 {
+		t.Errorf("hook called after removal")
+	}
+}
+
+func TestHooksConcurrent(t *testing.T) {
+
+	var mu sync.Mutex
+	count := 0
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			remove := errors.AddHook(func(errors.Event) {
+				mu.Lock()
+				count++
+				mu.Unlock()
+			})
+			errors.New("boom")
+			remove()
+		}()
+	}
+	wg.Wait()
+
+	if count < 8 {
+		t.Errorf("hooks called %d times, want at least 8", count)
+	}
+}
@@ -0,0 +1,131 @@
+package errors_test
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/ihleven/errors"
+	pkgerrors "github.com/pkg/errors"
+)
+
+// thirdParty is an error of another package implementing the interfaces of this one,
+// but not fmt.Formatter.
+type thirdParty struct {
+	cause error
+	pcs   []uintptr
+}
+
+func newThirdParty(cause error) *thirdParty {
+	pcs := make([]uintptr, 32)
+	return &thirdParty{cause, pcs[:runtime.Callers(2, pcs)]}
+}
+
+func (e *thirdParty) Error() string { return "third party" }
+func (e *thirdParty) Cause() error  { return e.cause }
+func (e *thirdParty) Code() int     { return int(errors.BadRequest) }
+func (e *thirdParty) StackTrace() errors.StackTrace {
+	st := make(errors.StackTrace, len(e.pcs))
+	for i, pc := range e.pcs {
+		st[i] = errors.Frame(pc)
+	}
+	return st
+}
+
+var (
+	_ errors.Causer      = (*thirdParty)(nil)
+	_ errors.Coder       = (*thirdParty)(nil)
+	_ errors.StackTracer = (*thirdParty)(nil)
+)
+
+func TestThirdPartyInterfaces(t *testing.T) {
+
+	root := fmt.Errorf("root")
+	err := errors.Wrap(fmt.Errorf("passing: %w", newThirdParty(root)), "wrapped")
+
+	if code := errors.Code(err); code != int(errors.BadRequest) {
+		t.Errorf("Code = %d, want %d", code, errors.BadRequest)
+	}
+	if cause := errors.Cause(err); cause != root {
+		t.Errorf("Cause = %v, want %v", cause, root)
+	}
+	if st := errors.GetStackTrace(err); len(st) == 0 || fmt.Sprintf("%n", st[0]) != "TestThirdPartyInterfaces" {
+		t.Errorf("GetStackTrace = %v, want the stack of the third party error", st)
+	}
+
+	layers := errors.Layers(err)
+	if len(layers) != 4 || len(layers[2].Stack) == 0 {
+		t.Fatalf("Layers = %+v, want the stack on the third party layer and no stack added by Wrap", layers)
+	}
+	if layers[2].Function != "TestThirdPartyInterfaces" {
+		t.Errorf("third party layer location = %s, want TestThirdPartyInterfaces", layers[2].Function)
+	}
+
+	text := fmt.Sprintf("%+v", errors.Wrap(newThirdParty(root), "wrapped"))
+	if !strings.Contains(text, "third party\ngithub.com/ihleven/errors_test.TestThirdPartyInterfaces\n") {
+		t.Errorf("%%+v lacks the stack of the third party error:\n%s", text)
+	}
+}
+
+func TestGetStackTrace(t *testing.T) {
+
+	if st := errors.GetStackTrace(fmt.Errorf("no stack")); st != nil {
+		t.Errorf("GetStackTrace of an error without stack = %v, want nil", st)
+	}
+
+	inner := errors.New("inner")
+	outer := errors.Wrap(fmt.Errorf("outer: %w", errors.Wrap(inner, "wrapped")))
+	if got, want := errors.GetStackTrace(outer), inner.(errors.StackTracer).StackTrace(); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("GetStackTrace = %v, want the stack of New %v", got, want)
+	}
+
+	// stacks of github.com/pkg/errors are found as well
+	pkgerr := pkgerrors.New("pkg")
+	err := errors.Wrap(fmt.Errorf("passing: %w", pkgerr))
+	if st := errors.GetStackTrace(err); len(st) == 0 || fmt.Sprintf("%n", st[0]) != "TestGetStackTrace" {
+		t.Errorf("GetStackTrace = %v, want the stack of pkg/errors", st)
+	}
+	if _, ok := err.(errors.StackTracer); ok {
+		t.Errorf("Wrap attached a stack although pkg/errors recorded one")
+	}
+}
+
+// TestStackBehindErrorf checks the stack of an error behind fmt.Errorf, which prints
+// its message only, is printed by %+v exactly once.
+func TestStackBehindErrorf(t *testing.T) {
+
+	requireStacks(t)
+	for name, cause := range map[string]error{
+		"pkg/errors": pkgerrors.New("boom"),
+		"New":        errors.New("boom"),
+	} {
+		err := errors.Wrap(fmt.Errorf("ctx: %w", cause), "outer")
+		text := fmt.Sprintf("%+v", err)
+		if n := strings.Count(text, "testing.tRunner"); n != 1 {
+			t.Errorf("%s: %%+v prints the stack %d times, want once:\n%s", name, n, text)
+		}
+		if !strings.HasPrefix(text, "outer\n\t--- at ") || !strings.Contains(text, "Caused by: ctx: boom\n") {
+			t.Errorf("%s: %%+v =\n%s", name, text)
+		}
+	}
+
+	// the stacks of joined errors are printed with their common frames elided
+	err := errors.Wrap(fmt.Errorf("ctx: %w", joined()), "outer")
+	text := fmt.Sprintf("%+v", err)
+	if n := strings.Count(text, "testing.tRunner"); n != 1 {
+		t.Errorf("%%+v prints testing.tRunner %d times, want once:\n%s", n, text)
+	}
+	if n := strings.Count(text, "errors_test.joined"); n != 2 {
+		t.Errorf("%%+v prints joined %d times, want it in both stacks:\n%s", n, text)
+	}
+	if !strings.Contains(text, "common frames elided") {
+		t.Errorf("%%+v lacks common frames marker:\n%s", text)
+	}
+}
+
+func joined() error {
+	first := pkgerrors.New("first")
+	second := pkgerrors.New("second")
+	return fmt.Errorf("%w, %w", first, second)
+}
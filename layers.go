@@ -0,0 +1,183 @@
+package errors
+
+import (
+	"encoding/json"
+	"runtime"
+	"strings"
+)
+
+// Layer describes one layer of an error chain.
+//
+// Layers created by Wrap carry the wrapping message and the location Wrap was called from.
+// Layers created by New and NewWithCode, as well as foreign errors Wrap had to attach a
+// stack trace to, carry the original message, the code and the recorded stack. The location
+// of those is the innermost frame of the stack. Any other error in the chain is reported
+// with its Error() message, along with its stack if it implements StackTracer, unless it
+// is a Transparent wrapper.
+type Layer struct {
+	Message   string                 // message added by this layer
+	Function  string                 // function which created the layer, if known
+	Package   string                 // import path of the package of Function, if known
+	File      string                 // file which created the layer, if known
+	Line      int                    // line which created the layer, if known
+	Code      ErrorCode              // code attached to the layer, NoCode if none
+	Fields    map[string]interface{} // fields attached to the layer with Field
+	Stack     StackTrace             // stack trace recorded by the layer, if any
+	More      int                    // number of frames beyond the recorded stack trace, see SetMaxStackDepth
+	CreatedBy []StackTrace           // stacks of the goroutines which launched the recording one with Go, innermost first
+	Err       error                  // the error value making up the layer
+}
+
+// Layers returns the layers of err's chain, outermost first.
+// The chain is traversed as by Walk.
+func Layers(err error) []Layer {
+
+	var layers []Layer
+	var merged error
+
+	Walk(err, func(e error) bool {
+		if merged != nil && same(e, merged) {
+			return true
+		}
+		layer := Layer{Code: NoCode, Err: e}
+
+		switch x := e.(type) {
+		case *withMessage:
+			layer.Message = x.msg
+			layer.File, layer.Function, layer.Line = location(x.frame)
+			layer.Package = packagePath(x.frame)
+		case *fundamental:
+			layer.Message = x.msg
+			layer.Stack, layer.More = x.StackTrace(), x.stack.more
+			layer.CreatedBy = x.stack.createdBy()
+		case *withStack:
+			// the stack and the error it was attached to form a single layer
+			merged = x.error
+			layer.Message = x.error.Error()
+			if c, ok := x.error.(Coder); ok {
+				layer.Code = ErrorCode(c.Code())
+			}
+			layer.Stack, layer.More = x.StackTrace(), x.stack.more
+			layer.CreatedBy = x.stack.createdBy()
+		default:
+			if t, ok := e.(Transparent); ok && t.Transparent() {
+				return true
+			}
+			layer.Message = e.Error()
+			if pcs, ok := stackOf(e); ok {
+				layer.Stack = frames(pcs)
+			}
+		}
+		if len(layer.Stack) > 0 {
+			layer.File, layer.Function, layer.Line = location(layer.Stack[0])
+			layer.Package = packagePath(layer.Stack[0])
+		}
+
+		if c, ok := e.(Coder); ok {
+			layer.Code = ErrorCode(c.Code())
+		}
+		if m, ok := e.(interface{ meta() *metadata }); ok {
+			layer.Fields = m.meta().fieldMap()
+		}
+		layers = append(layers, layer)
+		return true
+	})
+	return layers
+}
+
+// Transparent is implemented by errors merely wrapping their cause to hold additional
+// data for As, like retry.Error. Layers skips them if Transparent returns true, as they
+// add neither message, code nor stack to the chain.
+type Transparent interface {
+	Transparent() bool
+}
+
+// MarshalJSON encodes the layer as a JSON object. Empty fields are omitted and
+// the stack is encoded as a list of frames as returned by Frame.MarshalText,
+// followed by a "... N more frames" marker if frames beyond were not recorded.
+// The stacks of CreatedBy are encoded as "created_by", a list of such lists.
+// With the stack encoding EncodePCs, frames are encoded as the hexadecimal value of
+// the Frame, a return address, and the location is left to be resolved from the
+// stack along with the frames, using the BuildInfo encoded as "build".
+func (l Layer) MarshalJSON() ([]byte, error) {
+
+	type layer struct {
+		Message   string                 `json:"message"`
+		Function  string                 `json:"function,omitempty"`
+		Package   string                 `json:"package,omitempty"`
+		File      string                 `json:"file,omitempty"`
+		Line      int                    `json:"line,omitempty"`
+		Code      *int                   `json:"code,omitempty"`
+		Fields    map[string]interface{} `json:"fields,omitempty"`
+		Stack     []string               `json:"stack,omitempty"`
+		CreatedBy [][]string             `json:"created_by,omitempty"`
+		Build     *BuildInfo             `json:"build,omitempty"`
+	}
+
+	out := layer{
+		Message:  l.Message,
+		Function: l.Function,
+		Package:  l.Package,
+		File:     l.File,
+		Line:     l.Line,
+		Fields:   l.Fields,
+	}
+	texts := frameTexts
+	if StackEncoding(stackEncoding.Load()) == EncodePCs && len(l.Stack) > 0 {
+		// the location is the innermost frame of the stack
+		texts = pcTexts
+		out.Function, out.Package, out.File, out.Line = "", "", "", 0
+		build := Build()
+		out.Build = &build
+	}
+	out.Stack = texts(l.Stack)
+	if l.More > 0 {
+		out.Stack = append(out.Stack, moreFrames(l.More))
+	}
+	for _, st := range l.CreatedBy {
+		out.CreatedBy = append(out.CreatedBy, texts(st))
+	}
+	if l.Code != NoCode {
+		code := int(l.Code)
+		out.Code = &code
+	}
+	return json.Marshal(out)
+}
+
+// frameTexts returns the frames of st as returned by Frame.MarshalText.
+func frameTexts(st StackTrace) []string {
+	var texts []string
+	for _, f := range st {
+		text, _ := f.MarshalText()
+		texts = append(texts, string(text))
+	}
+	return texts
+}
+
+// location returns file, short function name and line of f the same way Wrap records them.
+func location(f Frame) (file string, function string, line int) {
+
+	fn := runtime.FuncForPC(f.pc())
+	if fn == nil {
+		return "unknown", "unknown", 0
+	}
+	file, line = fn.FileLine(f.pc())
+	if cleanPath != nil {
+		file = cleanPath(file)
+	}
+	return file, shortFuncName(fn), line
+}
+
+// packagePath returns the import path of the package of f's function, if known.
+func packagePath(f Frame) string {
+	fn := runtime.FuncForPC(f.pc())
+	if fn == nil {
+		return ""
+	}
+	name := fn.Name()
+	slash := strings.LastIndex(name, "/") + 1
+	if dot := strings.Index(name[slash:], "."); dot >= 0 {
+		return name[:slash+dot]
+	}
+	return ""
+}
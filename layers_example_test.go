@@ -0,0 +1,40 @@
+//go:build !errors_nostack
+
+package errors_test
+
+import (
+	"fmt"
+	"path"
+
+	"github.com/ihleven/errors"
+)
+
+func findUser() error {
+	return errors.NewWithCode(errors.NotFound, "no such user %q", "bob")
+}
+
+func loadProfile() error {
+	return errors.Wrap(findUser(), "loading profile")
+}
+
+func ExampleLayers() {
+	err := fmt.Errorf("handler: %w", loadProfile())
+
+	for _, l := range errors.Layers(err) {
+		fmt.Printf("%q", l.Message)
+		if l.Code != errors.NoCode {
+			fmt.Printf(" [%d]", l.Code)
+		}
+		if l.File != "" {
+			fmt.Printf(" at %s:%d (%s)", path.Base(l.File), l.Line, l.Function)
+		}
+		if len(l.Stack) > 0 {
+			fmt.Print(" with stack")
+		}
+		fmt.Println()
+	}
+	// Output:
+	// "handler: loading profile: no such user \"bob\""
+	// "loading profile" at layers_example_test.go:17 (loadProfile)
+	// "no such user \"bob\"" [404] at layers_example_test.go:13 (findUser) with stack
+}
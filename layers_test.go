@@ -0,0 +1,76 @@
+package errors_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/ihleven/errors"
+)
+
+func TestLayersJSON(t *testing.T) {
+
+	requireStacks(t)
+	layers := errors.Layers(errors.Wrap(errors.New("boom"), "outer"))
+	if len(layers) != 2 {
+		t.Fatalf("len(Layers) = %d, want 2", len(layers))
+	}
+
+	b, err := json.Marshal(layers[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	var outer map[string]interface{}
+	if err := json.Unmarshal(b, &outer); err != nil {
+		t.Fatal(err)
+	}
+	if outer["message"] != "outer" || outer["function"] != "TestLayersJSON" || outer["package"] != "github.com/ihleven/errors_test" {
+		t.Errorf("outer layer = %s", b)
+	}
+	if _, ok := outer["code"]; ok {
+		t.Errorf("outer layer without code encodes code: %s", b)
+	}
+
+	b, err = json.Marshal(layers[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	var inner struct {
+		Message string   `json:"message"`
+		Stack   []string `json:"stack"`
+	}
+	if err := json.Unmarshal(b, &inner); err != nil {
+		t.Fatal(err)
+	}
+	if inner.Message != "boom" || len(inner.Stack) == 0 {
+		t.Errorf("inner layer = %s", b)
+	}
+}
+
+// annotated is a foreign error holding data for errors.As, adding nothing to the chain.
+type annotated struct{ error }
+
+func (a annotated) Unwrap() error     { return a.error }
+func (a annotated) Transparent() bool { return true }
+
+// plain is a foreign error wrapping its cause without a message of its own.
+type plain struct{ error }
+
+func (p plain) Unwrap() error { return p.error }
+
+func TestLayersTransparent(t *testing.T) {
+
+	err := annotated{errors.Wrap(errors.New("boom"), "outer")}
+	layers := errors.Layers(err)
+	if len(layers) != 2 || layers[0].Message != "outer" {
+		t.Errorf("Layers = %+v, want the wrapped layers only", layers)
+	}
+
+	// other foreign errors remain layers, even without a message of their own
+	if n := len(errors.Layers(fmt.Errorf("ctx: %w", err))); n != 3 {
+		t.Errorf("%d layers, want 3", n)
+	}
+	if n := len(errors.Layers(plain{err})); n != 3 {
+		t.Errorf("%d layers, want 3", n)
+	}
+}
@@ -0,0 +1,187 @@
+package errors
+
+import (
+	"strconv"
+	"sync"
+	"sync/atomic"
+)
+
+// MetricLabels identify the counter an error is counted in.
+type MetricLabels struct {
+	Code     string // name the code is registered with, its number if not registered, "none" without code
+	Category string // category the code is registered with, "none" if there is none
+	Function string // function of the outermost layer of the error, "unknown" if not known
+}
+
+// OtherLabel replaces label values beyond the limit given by Metrics.MaxLabelValues.
+const OtherLabel = "other"
+
+// Counter counts errors by labels. Implementations must be safe for concurrent use.
+type Counter interface {
+	Inc(labels MetricLabels)
+}
+
+// Metrics configures the counting of errors, see EnableMetrics.
+type Metrics struct {
+	// Counter counts the errors. A nil Counter disables counting.
+	Counter Counter
+	// OnCreate counts errors as they are created by New, NewWithCode and NewCtx. Wrapping
+	// does not count an error again, so errors of other packages are only counted when
+	// passed to Report. Without OnCreate, errors are only counted when passed to Report.
+	OnCreate bool
+	// MaxLabelValues limits the number of distinct values of each label to guard against
+	// cardinality explosions. Further values are counted as OtherLabel. Defaults to 100.
+	MaxLabelValues int
+}
+
+type metricsState struct {
+	Metrics
+	codes, functions limiter
+	removeHook       func()
+}
+
+var metrics struct {
+	sync.Mutex                              // serializes EnableMetrics
+	state      atomic.Pointer[metricsState] // read without locking
+}
+
+// EnableMetrics starts counting errors as configured by m. Counting is disabled by default,
+// and disabled again by passing Metrics without Counter. Counting on creation is implemented
+// as a hook, see AddHook.
+func EnableMetrics(m Metrics) {
+
+	metrics.Lock()
+	defer metrics.Unlock()
+
+	if old := metrics.state.Load(); old != nil && old.removeHook != nil {
+		old.removeHook()
+	}
+	if m.Counter == nil {
+		metrics.state.Store(nil)
+		return
+	}
+	if m.MaxLabelValues <= 0 {
+		m.MaxLabelValues = 100
+	}
+
+	state := &metricsState{
+		Metrics:   m,
+		codes:     limiter{max: m.MaxLabelValues},
+		functions: limiter{max: m.MaxLabelValues},
+	}
+	if m.OnCreate {
+		state.removeHook = AddHook(func(e Event) {
+			if e.Kind == EventNew {
+				state.Counter.Inc(state.labels(e.Err))
+			}
+		})
+	}
+	metrics.state.Store(state)
+}
+
+// Report counts err with the Counter enabled by EnableMetrics. It is meant for counting errors
+// where they are handled, e.g. in request middleware, and labels them by the function of their
+// outermost layer. Report does nothing if err is nil or counting is disabled.
+func Report(err error) {
+	if m := metrics.state.Load(); m != nil && err != nil {
+		m.Counter.Inc(m.labels(err))
+	}
+}
+
+func (m *metricsState) labels(err error) MetricLabels {
+
+	labels := MetricLabels{Code: "none", Category: "none", Function: "unknown"}
+
+	if code := ErrorCode(Code(err)); code != NoCode {
+		info, ok := LookupCode(code)
+		if ok && info.Name != "" {
+			labels.Code = info.Name
+		} else {
+			labels.Code = m.codes.limit(strconv.Itoa(int(code)))
+		}
+		if ok && info.Category != "" {
+			labels.Category = info.Category
+		}
+	}
+
+	Walk(err, func(e error) bool {
+		var function string
+		switch x := e.(type) {
+		case *withMessage:
+			_, function, _ = location(x.frame)
+		case *fundamental:
+			_, function, _ = x.stack.location()
+		case *withStack:
+			_, function, _ = x.stack.location()
+		default:
+			return true
+		}
+		if function != "" {
+			labels.Function = function
+		}
+		return false
+	})
+	labels.Function = m.functions.limit(labels.Function)
+
+	return labels
+}
+
+// limiter passes through up to max distinct values and replaces any further value by OtherLabel.
+type limiter struct {
+	sync.Mutex
+	max  int
+	seen map[string]bool
+}
+
+func (l *limiter) limit(value string) string {
+
+	l.Lock()
+	defer l.Unlock()
+
+	if l.seen[value] {
+		return value
+	}
+	if len(l.seen) >= l.max {
+		return OtherLabel
+	}
+	if l.seen == nil {
+		l.seen = make(map[string]bool)
+	}
+	l.seen[value] = true
+	return value
+}
+
+// MemoryCounter is a Counter keeping the counts in memory, e.g. for tests.
+// The zero value is ready to use.
+type MemoryCounter struct {
+	mu     sync.Mutex
+	counts map[MetricLabels]int
+}
+
+// Inc implements Counter.
+func (c *MemoryCounter) Inc(labels MetricLabels) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.counts == nil {
+		c.counts = make(map[MetricLabels]int)
+	}
+	c.counts[labels]++
+}
+
+// Count returns the number of errors counted with the given labels.
+func (c *MemoryCounter) Count(labels MetricLabels) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.counts[labels]
+}
+
+// Counts returns a copy of all counts.
+func (c *MemoryCounter) Counts() map[MetricLabels]int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	counts := make(map[MetricLabels]int, len(c.counts))
+	for labels, n := range c.counts {
+		counts[labels] = n
+	}
+	return counts
+}
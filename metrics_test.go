@@ -0,0 +1,57 @@
+package errors_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/ihleven/errors"
+)
+
+func enableMetrics(t *testing.T, m errors.Metrics) {
+	errors.EnableMetrics(m)
+	t.Cleanup(func() { errors.EnableMetrics(errors.Metrics{}) })
+}
+
+func TestMetricsOnCreate(t *testing.T) {
+
+	requireStacks(t)
+	counter := &errors.MemoryCounter{}
+	enableMetrics(t, errors.Metrics{Counter: counter, OnCreate: true})
+
+	// wrapping does not count an error again
+	err := errors.NewWithCode(errors.NotFound, "missing")
+	errors.Wrap(errors.Wrap(err, "loading"), "handling", errors.Field("user", 1))
+	errors.Wrap(err)
+	errors.Wrap(fmt.Errorf("foreign"), "calling")
+	errors.New("other")
+
+	want := map[errors.MetricLabels]int{
+		{Code: "NotFound", Category: "client", Function: "TestMetricsOnCreate"}: 1,
+		{Code: "none", Category: "none", Function: "TestMetricsOnCreate"}:       1,
+	}
+	if got := counter.Counts(); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("Counts() = %v, want %v", got, want)
+	}
+}
+
+func TestMetricsReport(t *testing.T) {
+
+	counter := &errors.MemoryCounter{}
+	enableMetrics(t, errors.Metrics{Counter: counter, MaxLabelValues: 2})
+
+	errors.New("not counted")
+
+	for _, code := range []errors.ErrorCode{418, 419, 420} {
+		errors.Report(errors.Wrap(errors.NewWithCode(code, "teapot"), "brewing"))
+	}
+	errors.Report(nil)
+
+	want := map[errors.MetricLabels]int{
+		{Code: "418", Category: "none", Function: "TestMetricsReport"}:   1,
+		{Code: "419", Category: "none", Function: "TestMetricsReport"}:   1,
+		{Code: "other", Category: "none", Function: "TestMetricsReport"}: 1,
+	}
+	if got := counter.Counts(); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("Counts() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,6 @@
+//go:build errors_nostack
+
+package errors
+
+// stackDisabled disables recording stacks, set by the build tag errors_nostack.
+const stackDisabled = true
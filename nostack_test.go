@@ -0,0 +1,61 @@
+package errors_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/ihleven/errors"
+)
+
+// stacksDisabled reports whether the build tag errors_nostack disables stack traces.
+func stacksDisabled() bool {
+	return errors.GetStackTrace(errors.New("probe")) == nil
+}
+
+// requireStacks skips tests depending on stack traces if they are disabled.
+func requireStacks(t *testing.T) {
+	t.Helper()
+	if stacksDisabled() {
+		t.Skip("stack traces disabled by the build tag errors_nostack")
+	}
+}
+
+// TestNoStack checks errors degrade gracefully without stack traces.
+func TestNoStack(t *testing.T) {
+
+	if !stacksDisabled() {
+		t.Skip("requires the build tag errors_nostack")
+	}
+
+	var events []errors.Event
+	t.Cleanup(errors.AddHook(func(e errors.Event) { events = append(events, e) }))
+
+	err := errors.Wrap(fmt.Errorf("ctx: %w", errors.NewWithCode(errors.NotFound, "boom")), "outer")
+	created := events
+
+	if got := fmt.Sprintf("%+v", err); !strings.HasPrefix(got, "outer\n\t--- at ") || !strings.HasSuffix(got, "(TestNoStack)\nCaused by: ctx: boom\n") {
+		t.Errorf("%%+v =\n%s", got)
+	}
+	if got := fmt.Sprintf("%+v", errors.New("boom")); got != "boom" {
+		t.Errorf("%%+v of New = %q, want the message", got)
+	}
+	if st := errors.GetStackTrace(errors.Wrap(fmt.Errorf("foreign"))); st != nil {
+		t.Errorf("GetStackTrace = %v, want nil", st)
+	}
+
+	layers := errors.Layers(err)
+	if len(layers) != 3 || layers[0].Function != "TestNoStack" || layers[2].File != "" || layers[2].Stack != nil {
+		t.Fatalf("Layers = %+v", layers)
+	}
+	b, e := json.Marshal(layers[2])
+	if e != nil || string(b) != `{"message":"boom","code":404}` {
+		t.Errorf("inner layer = %s, %v", b, e)
+	}
+
+	if len(created) != 2 || created[0].Kind != errors.EventNew || created[0].Line != 0 ||
+		created[1].Kind != errors.EventWrap || created[1].Function != "TestNoStack" {
+		t.Errorf("events = %+v, want New without and Wrap with location", created)
+	}
+}
@@ -0,0 +1,168 @@
+package errors
+
+import (
+	"time"
+)
+
+// Option attaches metadata to an error. Options are passed to New, NewWithCode and Wrap
+// among their variadic arguments and may be mixed freely with the format arguments:
+//
+//	errors.Wrap(err, "loading user %d", id, errors.WithCode(errors.NotFound))
+//
+// This allows a single call site to add context to an error coming from a dependency
+// and reclassify it at the same time.
+type Option func(*options)
+
+// options collects the metadata and settings given by Options.
+type options struct {
+	metadata
+	skip int
+}
+
+// metadata is the information attached to an error through Options.
+type metadata struct {
+	code     ErrorCode
+	severity Severity
+	public   string
+	fields   []field
+	retry    Retryability
+	// retryAfter is the minimum time to wait before retrying
+	retryAfter time.Duration
+	// defined holds the Def and parameters of errors created by Def.New
+	defined interface{}
+}
+
+type field struct {
+	key   string
+	value interface{}
+}
+
+func (m *metadata) meta() *metadata { return m }
+
+// fieldMap returns the attached fields as a map, nil if there are none.
+// For keys attached more than once the last value wins.
+func (m *metadata) fieldMap() map[string]interface{} {
+	if len(m.fields) == 0 {
+		return nil
+	}
+	fields := make(map[string]interface{}, len(m.fields))
+	for _, f := range m.fields {
+		fields[f.key] = f.value
+	}
+	return fields
+}
+
+// Code returns the attached code, NoCode if none was attached.
+func (m *metadata) Code() int { return int(m.code) }
+
+// Is reports whether target is the ErrorCode attached.
+func (m *metadata) Is(target error) bool {
+	code, ok := target.(ErrorCode)
+	return ok && code != NoCode && code == m.code
+}
+
+// WithCode attaches the given code to the error.
+func WithCode(code ErrorCode) Option {
+	return func(o *options) { o.code = code }
+}
+
+// Field attaches a key value pair to the error. See Fields.
+func Field(key string, value interface{}) Option {
+	return func(o *options) { o.fields = append(o.fields, field{key, value}) }
+}
+
+// WithSeverity attaches the given severity to the error.
+func WithSeverity(severity Severity) Option {
+	return func(o *options) { o.severity = severity }
+}
+
+// Public attaches a message that is safe to be shown to users of an application,
+// in contrast to the error message which may reveal internals. See PublicMessage.
+func Public(msg string) Option {
+	return func(o *options) { o.public = msg }
+}
+
+// SkipFrames skips the given number of additional stack frames when recording
+// the location and stack trace of the error. It is meant for helper functions
+// creating or wrapping errors on behalf of their callers.
+func SkipFrames(n int) Option {
+	return func(o *options) { o.skip += n }
+}
+
+// isZero reports whether no metadata was attached.
+func (o *options) isZero() bool {
+	return o.code == NoCode && o.severity == SeverityUnset && o.public == "" && len(o.fields) == 0 &&
+		o.retry == RetryUnknown && o.retryAfter == 0
+}
+
+// parseArgs separates Options from the remaining arguments and applies them
+// on top of the given code.
+func parseArgs(code ErrorCode, args []interface{}) (options, []interface{}) {
+
+	// opts is only allocated if there are options, as it escapes to them
+	var opts *options
+	var rest []interface{}
+
+	for i, arg := range args {
+		option, ok := arg.(Option)
+		if !ok {
+			if rest != nil {
+				rest = append(rest, arg)
+			}
+			continue
+		}
+		if opts == nil {
+			opts = &options{metadata: metadata{code: code}}
+			rest = append(make([]interface{}, 0, len(args)-1), args[:i]...)
+		}
+		option(opts)
+	}
+
+	if opts == nil {
+		return options{metadata: metadata{code: code}}, args
+	}
+	return *opts, rest
+}
+
+// Fields returns the key value pairs attached to the errors of err's chain.
+// If a key was attached more than once, the outermost and, within a layer,
+// the last value wins.
+func Fields(err error) map[string]interface{} {
+
+	var fields map[string]interface{}
+
+	Walk(err, func(e error) bool {
+		m, ok := e.(interface{ meta() *metadata })
+		if !ok {
+			return true
+		}
+		own := m.meta().fields
+		for i := len(own) - 1; i >= 0; i-- {
+			f := own[i]
+			if fields == nil {
+				fields = make(map[string]interface{})
+			}
+			if _, ok := fields[f.key]; !ok {
+				fields[f.key] = f.value
+			}
+		}
+		return true
+	})
+	return fields
+}
+
+// PublicMessage returns the outermost message attached with Public to an error
+// of err's chain, or the empty string if there is none.
+func PublicMessage(err error) string {
+
+	var msg string
+
+	Walk(err, func(e error) bool {
+		if m, ok := e.(interface{ meta() *metadata }); ok && m.meta().public != "" {
+			msg = m.meta().public
+			return false
+		}
+		return true
+	})
+	return msg
+}
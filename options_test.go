@@ -0,0 +1,89 @@
+package errors_test
+
+import (
+	"io"
+	"path"
+	"testing"
+
+	"github.com/ihleven/errors"
+)
+
+func TestWrapOptions(t *testing.T) {
+
+	err := errors.Wrap(io.ErrUnexpectedEOF, "reading user %d", 42,
+		errors.WithCode(errors.BadRequest),
+		errors.Field("user", 42),
+		errors.Public("invalid request body"),
+	)
+
+	if got, want := err.Error(), "reading user 42: unexpected EOF"; got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+	if got := errors.Code(err); got != int(errors.BadRequest) {
+		t.Errorf("Code() = %d, want %d", got, errors.BadRequest)
+	}
+	if got := errors.Fields(err)["user"]; got != 42 {
+		t.Errorf("Fields()[user] = %v, want 42", got)
+	}
+	if got, want := errors.PublicMessage(err), "invalid request body"; got != want {
+		t.Errorf("PublicMessage() = %q, want %q", got, want)
+	}
+}
+
+func TestWrapOptionsOnly(t *testing.T) {
+
+	inner := errors.NewWithCode(errors.BadRequest, "invalid id", errors.Field("id", "x"))
+	err := errors.Wrap(inner, errors.WithCode(errors.NotFound), errors.Field("id", "y"))
+
+	if got, want := err.Error(), "invalid id"; got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+	if got := errors.Code(err); got != int(errors.NotFound) {
+		t.Errorf("Code() = %d, want %d", got, errors.NotFound)
+	}
+	if got := errors.GetCauseCode(err); got != int(errors.BadRequest) {
+		t.Errorf("GetCauseCode() = %d, want %d", got, errors.BadRequest)
+	}
+	if got := errors.Fields(err)["id"]; got != "y" {
+		t.Errorf("Fields()[id] = %v, want y", got)
+	}
+}
+
+func TestNewWithCodeOption(t *testing.T) {
+
+	err := errors.NewWithCode(errors.BadRequest, "%s", "invalid", errors.WithCode(errors.NotFound))
+
+	if got, want := err.Error(), "invalid"; got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+	if got := errors.Code(err); got != int(errors.NotFound) {
+		t.Errorf("Code() = %d, want %d", got, errors.NotFound)
+	}
+}
+
+func wrapHelper(err error) error {
+	return errors.Wrap(err, "helper", errors.SkipFrames(1))
+}
+
+func TestSkipFrames(t *testing.T) {
+
+	err := wrapHelper(errors.New("boom"))
+
+	layer := errors.Layers(err)[0]
+	if layer.Function != "TestSkipFrames" || path.Base(layer.File) != "options_test.go" {
+		t.Errorf("wrap location = %s (%s), want TestSkipFrames (options_test.go)", layer.File, layer.Function)
+	}
+}
+
+func TestPercentWithoutArgs(t *testing.T) {
+
+	if got, want := errors.New("disk 100%% full").Error(), "disk 100% full"; got != want {
+		t.Errorf("New().Error() = %q, want %q", got, want)
+	}
+	if got, want := errors.Wrap(io.EOF, "50%% done").Error(), "50% done: EOF"; got != want {
+		t.Errorf("Wrap().Error() = %q, want %q", got, want)
+	}
+	if got, want := errors.New("plain message").Error(), "plain message"; got != want {
+		t.Errorf("New().Error() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,131 @@
+// Package otelerrors records errors of github.com/ihleven/errors on OpenTelemetry spans.
+//
+// It lives in a module of its own, so that the errors package does not depend on OpenTelemetry.
+package otelerrors
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/ihleven/errors"
+)
+
+// Attribute keys set in addition to the exception attributes of the semantic conventions.
+const (
+	CodeKey      = attribute.Key("error.code")
+	CodeNameKey  = attribute.Key("error.code.name")
+	SeverityKey  = attribute.Key("error.severity")
+	FieldsPrefix = "error.field."
+)
+
+// Attribute keys of the exception event defined by the semantic conventions.
+const (
+	exceptionEvent      = "exception"
+	exceptionType       = attribute.Key("exception.type")
+	exceptionMessage    = attribute.Key("exception.message")
+	exceptionStacktrace = attribute.Key("exception.stacktrace")
+)
+
+// RecordError records err on span as an exception event and sets the status of span to error.
+//
+// The event carries
+//
+//   - exception.type: the name the code of err is registered with, or the type of its cause,
+//   - exception.message: the message of err including all layers,
+//   - exception.stacktrace: the deepest stack trace found in err's chain,
+//
+// as well as the attributes returned by Attributes. The code, severity and fields are
+// also added as attributes of the span itself. RecordError does nothing if err is nil.
+func RecordError(span trace.Span, err error, opts ...trace.EventOption) {
+
+	if err == nil {
+		return
+	}
+
+	attrs := Attributes(err)
+
+	event := []attribute.KeyValue{
+		exceptionType.String(typeName(err)),
+		exceptionMessage.String(err.Error()),
+	}
+	if st := stackTrace(err); st != "" {
+		event = append(event, exceptionStacktrace.String(st))
+	}
+	event = append(event, attrs...)
+
+	span.AddEvent(exceptionEvent, append(opts, trace.WithAttributes(event...))...)
+	span.SetStatus(codes.Error, err.Error())
+	span.SetAttributes(attrs...)
+}
+
+// Attributes returns the code, severity and fields of err as span attributes.
+// Fields are prefixed with FieldsPrefix.
+func Attributes(err error) []attribute.KeyValue {
+
+	var attrs []attribute.KeyValue
+
+	if code := errors.ErrorCode(errors.Code(err)); code != errors.NoCode {
+		attrs = append(attrs, CodeKey.Int(int(code)))
+		if info, ok := errors.LookupCode(code); ok && info.Name != "" {
+			attrs = append(attrs, CodeNameKey.String(info.Name))
+		}
+	}
+	attrs = append(attrs, SeverityKey.String(errors.GetSeverity(err).String()))
+
+	for key, value := range errors.Fields(err) {
+		attrs = append(attrs, attributeOf(FieldsPrefix+key, value))
+	}
+	return attrs
+}
+
+func attributeOf(key string, value interface{}) attribute.KeyValue {
+	k := attribute.Key(key)
+	switch v := value.(type) {
+	case string:
+		return k.String(v)
+	case bool:
+		return k.Bool(v)
+	case int:
+		return k.Int(v)
+	case int64:
+		return k.Int64(v)
+	case float64:
+		return k.Float64(v)
+	case fmt.Stringer:
+		return k.String(v.String())
+	}
+	return k.String(fmt.Sprint(value))
+}
+
+// typeName returns the registered name of err's code or the type of its cause.
+// The internal types of package errors are reported as "error".
+func typeName(err error) string {
+	if info, ok := errors.LookupCode(errors.ErrorCode(errors.Code(err))); ok && info.Name != "" {
+		return info.Name
+	}
+	t := reflect.TypeOf(errors.Cause(err))
+	if t.Kind() == reflect.Pointer && t.Elem().PkgPath() == errorsPath || t.PkgPath() == errorsPath {
+		return "error"
+	}
+	return t.String()
+}
+
+// errorsPath is the import path of package errors.
+var errorsPath = reflect.TypeOf(errors.NoCode).PkgPath()
+
+// stackTrace renders the deepest stack trace of err's chain, one "function\n\tfile:line" per frame.
+func stackTrace(err error) string {
+
+	var st errors.StackTrace
+	for _, layer := range errors.Layers(err) {
+		if len(layer.Stack) > 0 {
+			st = layer.Stack
+		}
+	}
+	return strings.TrimPrefix(fmt.Sprintf("%+v", st), "\n")
+}
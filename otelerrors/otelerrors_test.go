@@ -0,0 +1,116 @@
+package otelerrors_test
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/ihleven/errors"
+	"github.com/ihleven/errors/otelerrors"
+)
+
+func findUser() error {
+	return errors.NewWithCode(errors.NotFound, "no such user", errors.Field("user", "bob"))
+}
+
+func TestRecordError(t *testing.T) {
+
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+
+	_, span := provider.Tracer("test").Start(context.Background(), "load user")
+	otelerrors.RecordError(span, errors.Wrap(findUser(), "loading profile"))
+	span.End()
+
+	spans := exporter.GetSpans()
+	if len(spans) != 1 {
+		t.Fatalf("got %d spans, want 1", len(spans))
+	}
+	s := spans[0]
+
+	if s.Status.Code != codes.Error || s.Status.Description != "loading profile: no such user" {
+		t.Errorf("status = %v", s.Status)
+	}
+	if len(s.Events) != 1 || s.Events[0].Name != "exception" {
+		t.Fatalf("events = %v, want one exception event", s.Events)
+	}
+
+	event := attrs(s.Events[0].Attributes)
+	if got := event["exception.type"].AsString(); got != "NotFound" {
+		t.Errorf("exception.type = %q, want NotFound", got)
+	}
+	if got := event["exception.message"].AsString(); got != "loading profile: no such user" {
+		t.Errorf("exception.message = %q", got)
+	}
+	if errors.GetStackTrace(errors.New("probe")) == nil {
+		// stack traces are disabled by the build tag errors_nostack
+		if got := event["exception.stacktrace"].AsString(); got != "" {
+			t.Errorf("exception.stacktrace = %q, want none", got)
+		}
+	} else if got := event["exception.stacktrace"].AsString(); !strings.HasPrefix(got, "github.com/ihleven/errors/otelerrors_test.findUser\n\t") {
+		t.Errorf("exception.stacktrace = %q, want it to start with findUser", got)
+	}
+
+	attributes := attrs(s.Attributes)
+	if got := attributes["error.code"].AsInt64(); got != 404 {
+		t.Errorf("error.code = %d, want 404", got)
+	}
+	if got := attributes["error.field.user"].AsString(); got != "bob" {
+		t.Errorf("error.field.user = %q, want bob", got)
+	}
+	if got := attributes["error.severity"].AsString(); got != "debug" {
+		t.Errorf("error.severity = %q, want debug", got)
+	}
+}
+
+func TestExceptionType(t *testing.T) {
+
+	for _, tt := range []struct {
+		err  error
+		want string
+	}{
+		{errors.Wrap(errors.New("boom"), "outer"), "error"},
+		{errors.Wrap(io.EOF, "reading"), "*errors.errorString"},
+		{errors.Wrap(errors.New("code", errors.WithCode(errors.NotFound)), "outer"), "NotFound"},
+	} {
+		exporter := tracetest.NewInMemoryExporter()
+		provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+
+		_, span := provider.Tracer("test").Start(context.Background(), "op")
+		otelerrors.RecordError(span, tt.err)
+		span.End()
+
+		event := attrs(exporter.GetSpans()[0].Events[0].Attributes)
+		if got := event["exception.type"].AsString(); got != tt.want {
+			t.Errorf("exception.type of %v = %q, want %q", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestRecordNil(t *testing.T) {
+
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+
+	_, span := provider.Tracer("test").Start(context.Background(), "noop")
+	otelerrors.RecordError(span, nil)
+	span.End()
+
+	if s := exporter.GetSpans()[0]; len(s.Events) != 0 || s.Status.Code != codes.Unset {
+		t.Errorf("span = %+v, want no events and unset status", s)
+	}
+}
+
+func attrs(kvs []attribute.KeyValue) map[attribute.Key]attribute.Value {
+	m := make(map[attribute.Key]attribute.Value, len(kvs))
+	for _, kv := range kvs {
+		m[kv.Key] = kv.Value
+	}
+	return m
+}
@@ -0,0 +1,46 @@
+// Package promerrors exposes the error counts of github.com/ihleven/errors as Prometheus metrics.
+//
+// It lives in a module of its own, so that the errors package does not depend on the
+// Prometheus client.
+//
+//	counter := promerrors.NewCounter(prometheus.CounterOpts{Name: "errors_total", Help: "Errors by code and function."})
+//	prometheus.MustRegister(counter)
+//	errors.EnableMetrics(errors.Metrics{Counter: counter, OnCreate: true})
+package promerrors
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/ihleven/errors"
+)
+
+// Label names of the counter.
+const (
+	CodeLabel     = "code"
+	CategoryLabel = "category"
+	FunctionLabel = "function"
+)
+
+// Counter is an errors.Counter backed by a Prometheus counter vector with the labels
+// code, category and function. It is a prometheus.Collector to be registered.
+type Counter struct {
+	vec *prometheus.CounterVec
+}
+
+// NewCounter returns a Counter with the given options.
+func NewCounter(opts prometheus.CounterOpts) *Counter {
+	return &Counter{
+		vec: prometheus.NewCounterVec(opts, []string{CodeLabel, CategoryLabel, FunctionLabel}),
+	}
+}
+
+// Inc implements errors.Counter.
+func (c *Counter) Inc(labels errors.MetricLabels) {
+	c.vec.WithLabelValues(labels.Code, labels.Category, labels.Function).Inc()
+}
+
+// Describe implements prometheus.Collector.
+func (c *Counter) Describe(ch chan<- *prometheus.Desc) { c.vec.Describe(ch) }
+
+// Collect implements prometheus.Collector.
+func (c *Counter) Collect(ch chan<- prometheus.Metric) { c.vec.Collect(ch) }
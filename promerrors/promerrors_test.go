@@ -0,0 +1,37 @@
+package promerrors_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/ihleven/errors"
+	"github.com/ihleven/errors/promerrors"
+)
+
+func TestCounter(t *testing.T) {
+
+	if errors.GetStackTrace(errors.New("probe")) == nil {
+		t.Skip("stack traces disabled by the build tag errors_nostack")
+	}
+	counter := promerrors.NewCounter(prometheus.CounterOpts{Name: "errors_total", Help: "Errors by code and function."})
+	registry := prometheus.NewPedanticRegistry()
+	registry.MustRegister(counter)
+
+	errors.EnableMetrics(errors.Metrics{Counter: counter, OnCreate: true})
+	defer errors.EnableMetrics(errors.Metrics{})
+
+	err := errors.NewWithCode(errors.NotFound, "missing")
+	errors.Wrap(err, "loading")
+
+	expected := `
+# HELP errors_total Errors by code and function.
+# TYPE errors_total counter
+errors_total{category="client",code="NotFound",function="TestCounter"} 1
+`
+	if err := testutil.GatherAndCompare(registry, strings.NewReader(expected), "errors_total"); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,185 @@
+// Package retry runs operations until they succeed, backing off exponentially between attempts.
+//
+// Whether a failed operation is retried is decided by the metadata of the returned error,
+// see errors.GetRetryability: errors marked permanent stop retrying immediately, all others
+// are retried until the attempts are exhausted or the context is done.
+package retry
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/ihleven/errors"
+)
+
+// Clock abstracts the passing of time, so that tests can run without actually waiting.
+type Clock interface {
+	// After waits for the duration to elapse and then sends the current time on the returned channel.
+	After(d time.Duration) <-chan time.Time
+}
+
+type realClock struct{}
+
+func (realClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+// Policy configures how often and how fast operations are retried.
+type Policy struct {
+	// MaxAttempts is the maximum number of attempts, including the first one.
+	MaxAttempts int
+	// InitialInterval is the delay after the first failed attempt.
+	InitialInterval time.Duration
+	// MaxInterval caps the delay between attempts.
+	MaxInterval time.Duration
+	// Multiplier is the factor the delay grows by after each failed attempt.
+	Multiplier float64
+	// Jitter randomizes each delay by up to the given fraction, e.g. 0.2 for ±20%.
+	Jitter float64
+	// Clock is used to wait between attempts. Nil means real time.
+	Clock Clock
+}
+
+// DefaultPolicy is the policy used by Do.
+var DefaultPolicy = Policy{
+	MaxAttempts:     5,
+	InitialInterval: 100 * time.Millisecond,
+	MaxInterval:     10 * time.Second,
+	Multiplier:      2,
+	Jitter:          0.2,
+}
+
+// Attempt describes a failed attempt.
+type Attempt struct {
+	Err   error         // error returned by the attempt
+	Delay time.Duration // delay before the next attempt, zero for the last one
+}
+
+// Error is the error returned by Do if fn did not succeed. It wraps the last error returned
+// by fn, or the error of ctx if fn was never called, annotated with the reason to give up,
+// and holds the history of attempts. Its message and formatting are those of the error
+// wrapped, and as it is errors.Transparent it adds no layer of its own to errors.Layers.
+type Error struct {
+	err      error
+	attempts []Attempt
+}
+
+func (e *Error) Error() string { return e.err.Error() }
+
+// Unwrap returns the error wrapped.
+func (e *Error) Unwrap() error { return e.err }
+
+// Attempts returns the history of failed attempts, oldest first.
+func (e *Error) Attempts() []Attempt { return e.attempts }
+
+// Transparent returns true, see errors.Transparent.
+func (e *Error) Transparent() bool { return true }
+
+// Format formats the error wrapped.
+func (e *Error) Format(s fmt.State, verb rune) {
+	fmt.Fprintf(s, fmt.FormatString(s, verb), e.err)
+}
+
+// Do calls fn until it succeeds using DefaultPolicy. See Policy.Do.
+func Do(ctx context.Context, fn func() error) error {
+	return do(ctx, DefaultPolicy, fn)
+}
+
+// Do calls fn until it returns nil, an error marked as permanent, the attempts are
+// exhausted or ctx is done. Between attempts it waits for an exponentially growing delay,
+// or longer if the error carries a hint to do so (see errors.WithRetryAfter).
+//
+// If fn did not succeed, the last error returned by fn, or the error of ctx if fn was
+// never called, is returned as Error holding the history of attempts, see Attempts.
+func (p Policy) Do(ctx context.Context, fn func() error) error {
+	return do(ctx, p, fn)
+}
+
+// do implements Do and Policy.Do. It has to be called directly by them for
+// the wrapping location to be their caller.
+func do(ctx context.Context, p Policy, fn func() error) error {
+
+	clock := p.Clock
+	if clock == nil {
+		clock = realClock{}
+	}
+
+	var attempts []Attempt
+
+	for n := 1; ; n++ {
+		if err := ctx.Err(); err != nil {
+			if n == 1 {
+				return giveUp(err, attempts, "retry: no attempt made")
+			}
+			return giveUp(err, attempts, "retry: stopped after %d attempts", n-1)
+		}
+
+		err := fn()
+		if err == nil {
+			return nil
+		}
+		attempts = append(attempts, Attempt{Err: err})
+
+		if errors.IsPermanent(err) {
+			return giveUp(err, attempts, "retry: permanent error after %d attempts", n)
+		}
+		if p.MaxAttempts > 0 && n >= p.MaxAttempts {
+			return giveUp(err, attempts, "retry: giving up after %d attempts", n)
+		}
+
+		delay := p.delay(n)
+		if hint, ok := errors.RetryAfter(err); ok && hint > delay {
+			delay = hint
+		}
+		attempts[n-1].Delay = delay
+
+		select {
+		case <-ctx.Done():
+			attempts[n-1].Delay = 0
+			return giveUp(err, attempts, "retry: %v after %d attempts", ctx.Err(), n)
+		case <-clock.After(delay):
+		}
+	}
+}
+
+// giveUp wraps err with the reason to give up and the attempts. It has to be called
+// directly by do.
+func giveUp(err error, attempts []Attempt, format string, args ...interface{}) error {
+	args = append(args, errors.SkipFrames(3))
+	return &Error{errors.Wrap(err, append([]interface{}{format}, args...)...), attempts}
+}
+
+// delay returns the delay after the n-th failed attempt.
+func (p Policy) delay(n int) time.Duration {
+
+	multiplier := p.Multiplier
+	if multiplier < 1 {
+		multiplier = 1
+	}
+
+	// without MaxInterval the delay is capped by the longest duration, as the growing
+	// delay would overflow time.Duration, turn negative and not wait at all
+	max := float64(math.MaxInt64)
+	if p.MaxInterval > 0 {
+		max = float64(p.MaxInterval)
+	}
+
+	d := math.Min(float64(p.InitialInterval)*math.Pow(multiplier, float64(n-1)), max)
+	if p.Jitter > 0 {
+		d += d * p.Jitter * (2*rand.Float64() - 1)
+	}
+	if d >= float64(math.MaxInt64) {
+		return math.MaxInt64
+	}
+	return time.Duration(d)
+}
+
+// Attempts returns the history of failed attempts of the Error in err's chain, oldest first.
+func Attempts(err error) []Attempt {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.attempts
+	}
+	return nil
+}
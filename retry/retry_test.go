@@ -0,0 +1,204 @@
+package retry_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/ihleven/errors"
+	"github.com/ihleven/errors/retry"
+)
+
+// fakeClock returns from After immediately and records the delays it was asked to wait.
+type fakeClock struct {
+	delays []time.Duration
+}
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.delays = append(c.delays, d)
+	ch := make(chan time.Time, 1)
+	ch <- time.Time{}
+	return ch
+}
+
+func policy(clock retry.Clock) retry.Policy {
+	return retry.Policy{
+		MaxAttempts:     4,
+		InitialInterval: time.Second,
+		MaxInterval:     3 * time.Second,
+		Multiplier:      2,
+		Clock:           clock,
+	}
+}
+
+func TestDoSucceeds(t *testing.T) {
+
+	clock := &fakeClock{}
+	calls := 0
+
+	err := policy(clock).Do(context.Background(), func() error {
+		calls++
+		if calls < 3 {
+			return io.ErrUnexpectedEOF
+		}
+		return nil
+	})
+
+	if err != nil {
+		t.Fatalf("Do() = %v, want nil", err)
+	}
+	if calls != 3 {
+		t.Errorf("calls = %d, want 3", calls)
+	}
+	if want := []time.Duration{time.Second, 2 * time.Second}; !equal(clock.delays, want) {
+		t.Errorf("delays = %v, want %v", clock.delays, want)
+	}
+}
+
+func TestDoGivesUp(t *testing.T) {
+
+	clock := &fakeClock{}
+
+	err := policy(clock).Do(context.Background(), func() error {
+		return io.ErrUnexpectedEOF
+	})
+
+	if !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Fatalf("Do() = %v, want wrapped io.ErrUnexpectedEOF", err)
+	}
+	if want := []time.Duration{time.Second, 2 * time.Second, 3 * time.Second}; !equal(clock.delays, want) {
+		t.Errorf("delays = %v, want %v", clock.delays, want)
+	}
+	attempts := retry.Attempts(err)
+	if len(attempts) != 4 {
+		t.Fatalf("len(Attempts()) = %d, want 4", len(attempts))
+	}
+	if attempts[3].Delay != 0 || attempts[2].Delay != 3*time.Second {
+		t.Errorf("Attempts() = %v", attempts)
+	}
+	if layer := errors.Layers(err)[0]; layer.Function != "TestDoGivesUp" || path.Base(layer.File) != "retry_test.go" {
+		t.Errorf("wrapped at %s (%s), want retry_test.go (TestDoGivesUp)", layer.File, layer.Function)
+	}
+
+	// the attempts are held by the returned Error, not exported as fields
+	var retryErr *retry.Error
+	if !errors.As(err, &retryErr) || len(retryErr.Attempts()) != 4 {
+		t.Errorf("As(*retry.Error) failed for %v", err)
+	}
+	if fields := errors.Fields(err); len(fields) != 0 {
+		t.Errorf("Fields = %v, want none", fields)
+	}
+	if n := len(errors.Layers(err)); n != 2 {
+		t.Errorf("%d layers, want the reason to give up and the error of fn", n)
+	}
+	if got, want := fmt.Sprintf("%+v", err), fmt.Sprintf("%+v", retryErr.Unwrap()); got != want {
+		t.Errorf("%%+v =\n%s\nwant the error wrapped\n%s", got, want)
+	}
+}
+
+func TestDelayOverflow(t *testing.T) {
+
+	clock := &fakeClock{}
+	p := retry.Policy{MaxAttempts: 100, InitialInterval: time.Second, Multiplier: 2, Jitter: 0.2, Clock: clock}
+	p.Do(context.Background(), func() error { return io.ErrUnexpectedEOF })
+
+	for i, d := range clock.delays {
+		if d <= 0 || (i > 0 && d < clock.delays[i-1]/2) {
+			t.Fatalf("delays = %v, want growing delays", clock.delays[:i+1])
+		}
+	}
+}
+
+func TestDoStopsOnPermanent(t *testing.T) {
+
+	clock := &fakeClock{}
+	calls := 0
+
+	err := policy(clock).Do(context.Background(), func() error {
+		calls++
+		return errors.NewWithCode(errors.NotFound, "no such user")
+	})
+
+	if calls != 1 || len(clock.delays) != 0 {
+		t.Errorf("calls = %d, delays = %v, want a single call", calls, clock.delays)
+	}
+	if !errors.Is(err, errors.NotFound) {
+		t.Errorf("Do() = %v, want NotFound", err)
+	}
+}
+
+func TestDoCanceled(t *testing.T) {
+
+	ctx, cancel := context.WithCancel(context.Background())
+	calls := 0
+
+	err := retry.Policy{MaxAttempts: 10, InitialInterval: time.Hour}.Do(ctx, func() error {
+		calls++
+		cancel()
+		return errors.New("boom", errors.Temporary())
+	})
+
+	if calls != 1 {
+		t.Errorf("calls = %d, want 1", calls)
+	}
+	if len(retry.Attempts(err)) != 1 {
+		t.Errorf("Attempts() = %v, want one attempt", retry.Attempts(err))
+	}
+
+	err = retry.Do(ctx, func() error {
+		t.Fatal("fn called with canceled context")
+		return nil
+	})
+	if !errors.Is(err, context.Canceled) || err.Error() != "retry: no attempt made: context canceled" {
+		t.Errorf("Do() = %v, want context.Canceled", err)
+	}
+}
+
+func TestJitter(t *testing.T) {
+
+	clock := &fakeClock{}
+	p := policy(clock)
+	p.Jitter = 0.5
+	p.MaxAttempts = 2
+
+	p.Do(context.Background(), func() error { return io.ErrUnexpectedEOF })
+
+	if d := clock.delays[0]; d < time.Second/2 || d > 3*time.Second/2 {
+		t.Errorf("delay = %v, want within 0.5s and 1.5s", d)
+	}
+}
+
+func equal(a, b []time.Duration) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestDoHonoursRetryAfter(t *testing.T) {
+
+	clock := &fakeClock{}
+	p := policy(clock)
+	p.MaxAttempts = 3
+	calls := 0
+
+	p.Do(context.Background(), func() error {
+		calls++
+		if calls == 1 {
+			return errors.Wrap(errors.New("rate limited", errors.WithRetryAfter(30*time.Second)), "calling api")
+		}
+		return errors.New("rate limited", errors.WithRetryAfter(time.Millisecond))
+	})
+
+	if want := []time.Duration{30 * time.Second, 2 * time.Second}; !equal(clock.delays, want) {
+		t.Errorf("delays = %v, want %v", clock.delays, want)
+	}
+}
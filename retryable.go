@@ -0,0 +1,109 @@
+package errors
+
+import (
+	"context"
+	"time"
+)
+
+// Retryability classifies whether an operation that failed with an error is worth retrying.
+type Retryability int
+
+const (
+	// RetryUnknown means nothing is known about the error, retrying may or may not help.
+	RetryUnknown Retryability = iota
+	// RetryTemporary means the failure is temporary and retrying may succeed.
+	RetryTemporary
+	// RetryPermanent means retrying will fail again.
+	RetryPermanent
+)
+
+func (r Retryability) String() string {
+	switch r {
+	case RetryTemporary:
+		return "temporary"
+	case RetryPermanent:
+		return "permanent"
+	}
+	return "unknown"
+}
+
+// Temporary marks the error as temporary, retrying the failed operation may succeed.
+func Temporary() Option {
+	return func(o *options) { o.retry = RetryTemporary }
+}
+
+// Permanent marks the error as permanent, retrying the failed operation will fail again.
+func Permanent() Option {
+	return func(o *options) { o.retry = RetryPermanent }
+}
+
+// WithRetryAfter attaches a hint to the error not to retry the failed operation before
+// the given duration has passed, e.g. as requested by a Retry-After header of a rate
+// limited downstream service. See RetryAfter.
+func WithRetryAfter(d time.Duration) Option {
+	return func(o *options) { o.retryAfter = d }
+}
+
+// RetryAfter returns the outermost hint attached to err's chain with WithRetryAfter.
+// It reports false if there is none.
+func RetryAfter(err error) (time.Duration, bool) {
+
+	var d time.Duration
+	found := false
+
+	Walk(err, func(e error) bool {
+		if m, ok := e.(interface{ meta() *metadata }); ok && m.meta().retryAfter > 0 {
+			d, found = m.meta().retryAfter, true
+		}
+		return !found
+	})
+	return d, found
+}
+
+// GetRetryability returns whether the operation that failed with err is worth retrying.
+// It is resolved along err's chain as follows:
+//
+//  1. the outermost of
+//     - a marker attached with Temporary or Permanent,
+//     - an error with a method Timeout() bool returning true, like net.Error
+//     or context.DeadlineExceeded, which is temporary,
+//     - context.Canceled, which is permanent,
+//  2. the retryability registered for the code of err (see RegisterCode),
+//  3. RetryUnknown.
+func GetRetryability(err error) Retryability {
+
+	if err == nil {
+		return RetryUnknown
+	}
+
+	retry := RetryUnknown
+
+	Walk(err, func(e error) bool {
+		if m, ok := e.(interface{ meta() *metadata }); ok && m.meta().retry != RetryUnknown {
+			retry = m.meta().retry
+		} else if t, ok := e.(interface{ Timeout() bool }); ok && t.Timeout() {
+			retry = RetryTemporary
+		} else if e == context.Canceled {
+			retry = RetryPermanent
+		}
+		return retry == RetryUnknown
+	})
+	if retry != RetryUnknown {
+		return retry
+	}
+
+	if info, ok := LookupCode(ErrorCode(Code(err))); ok {
+		return info.Retry
+	}
+	return RetryUnknown
+}
+
+// IsRetryable reports whether err is known to be temporary.
+func IsRetryable(err error) bool {
+	return GetRetryability(err) == RetryTemporary
+}
+
+// IsPermanent reports whether err is known to be permanent.
+func IsPermanent(err error) bool {
+	return GetRetryability(err) == RetryPermanent
+}
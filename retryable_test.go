@@ -0,0 +1,58 @@
+package errors_test
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/ihleven/errors"
+)
+
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func TestGetRetryability(t *testing.T) {
+
+	tests := []struct {
+		name string
+		err  error
+		want errors.Retryability
+	}{
+		{"nil", nil, errors.RetryUnknown},
+		{"plain", errors.New("boom"), errors.RetryUnknown},
+		{"marked temporary", errors.New("boom", errors.Temporary()), errors.RetryTemporary},
+		{"marked permanent", errors.Wrap(fmt.Errorf("boom"), "calling", errors.Permanent()), errors.RetryPermanent},
+		{"code default", errors.Wrap(errors.NewWithCode(errors.NotFound, "missing"), "loading"), errors.RetryPermanent},
+		{"marker overrides code", errors.NewWithCode(errors.NotFound, "missing", errors.Temporary()), errors.RetryTemporary},
+		{"deadline", fmt.Errorf("calling: %w", context.DeadlineExceeded), errors.RetryTemporary},
+		{"canceled", errors.Wrap(context.Canceled, "calling"), errors.RetryPermanent},
+		{"net timeout", errors.Wrap(&net.OpError{Op: "dial", Err: timeoutError{}}, "dialing"), errors.RetryTemporary},
+	}
+	for _, tt := range tests {
+		if got := errors.GetRetryability(tt.err); got != tt.want {
+			t.Errorf("%s: GetRetryability() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+
+	err := errors.Wrap(errors.New("rate limited", errors.WithRetryAfter(time.Minute)), "calling api")
+	if d, ok := errors.RetryAfter(fmt.Errorf("handler: %w", err)); !ok || d != time.Minute {
+		t.Errorf("RetryAfter() = %v, %v, want %v, true", d, ok, time.Minute)
+	}
+
+	err = errors.Wrap(err, "retrying", errors.WithRetryAfter(time.Second))
+	if d, _ := errors.RetryAfter(err); d != time.Second {
+		t.Errorf("RetryAfter() = %v, want outermost hint %v", d, time.Second)
+	}
+
+	if _, ok := errors.RetryAfter(errors.New("boom")); ok {
+		t.Errorf("RetryAfter() without hint reports true")
+	}
+}
@@ -0,0 +1,222 @@
+package sentryerrors
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"runtime"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/ihleven/errors"
+)
+
+// Event is the subset of a Sentry event this package fills in.
+// See https://develop.sentry.dev/sdk/event-payloads/.
+type Event struct {
+	EventID     string            `json:"event_id"`
+	Timestamp   time.Time         `json:"timestamp"`
+	Platform    string            `json:"platform"`
+	Level       string            `json:"level"`
+	Release     string            `json:"release,omitempty"`
+	Environment string            `json:"environment,omitempty"`
+	Exception   Exceptions        `json:"exception"`
+	Tags        map[string]string `json:"tags,omitempty"`
+}
+
+// Exceptions lists the exceptions of an event, the oldest first.
+type Exceptions struct {
+	Values []Exception `json:"values"`
+}
+
+// Exception describes a layer of an error chain.
+type Exception struct {
+	Type       string      `json:"type"`
+	Value      string      `json:"value"`
+	Stacktrace *Stacktrace `json:"stacktrace,omitempty"`
+}
+
+// Stacktrace lists the frames of a stack, the oldest first.
+type Stacktrace struct {
+	Frames []Frame `json:"frames"`
+}
+
+// Frame is a stack frame.
+type Frame struct {
+	Function string `json:"function"`
+	Module   string `json:"module,omitempty"`
+	Filename string `json:"filename,omitempty"`
+	AbsPath  string `json:"abs_path,omitempty"`
+	Lineno   int    `json:"lineno,omitempty"`
+	InApp    bool   `json:"in_app"`
+}
+
+// NewEvent converts err into a Sentry event. Each layer of err's chain becomes an exception,
+// the innermost first as Sentry expects. Layers created by New carry their stack trace,
+// layers created by Wrap a single frame pointing at the Wrap call. Frames of functions in
+// packages below module are marked as in app. The code and fields of err become tags.
+func NewEvent(err error, module string) *Event {
+
+	event := &Event{
+		EventID:   newEventID(),
+		Timestamp: time.Now().UTC(),
+		Platform:  "go",
+		Level:     level(errors.GetSeverity(err)),
+		Tags:      tags(err),
+	}
+
+	layers := errors.Layers(err)
+	for i := len(layers) - 1; i >= 0; i-- {
+		event.Exception.Values = append(event.Exception.Values, exception(layers[i], module))
+	}
+	return event
+}
+
+func exception(layer errors.Layer, module string) Exception {
+
+	ex := Exception{
+		Type:  typeName(layer),
+		Value: layer.Message,
+	}
+
+	switch {
+	case len(layer.Stack) > 0:
+		ex.Stacktrace = &Stacktrace{}
+		for i := len(layer.Stack) - 1; i >= 0; i-- {
+			ex.Stacktrace.Frames = append(ex.Stacktrace.Frames, frame(layer.Stack[i], module))
+		}
+	case layer.Function != "":
+		ex.Stacktrace = &Stacktrace{Frames: []Frame{{
+			Function: layer.Function,
+			Module:   layer.Package,
+			Filename: layer.File,
+			Lineno:   layer.Line,
+			InApp:    inApp(layer.Package, module),
+		}}}
+	}
+	return ex
+}
+
+func frame(f errors.Frame, module string) Frame {
+
+	// a Frame interpreted as uintptr is the program counter + 1
+	pc := uintptr(f) - 1
+
+	fn := runtime.FuncForPC(pc)
+	if fn == nil {
+		return Frame{Function: "unknown"}
+	}
+	file, line := fn.FileLine(pc)
+	pkg, function := splitFuncName(fn.Name())
+
+	return Frame{
+		Function: function,
+		Module:   pkg,
+		Filename: errors.RemoveGoPath(file),
+		AbsPath:  file,
+		Lineno:   line,
+		InApp:    inApp(pkg, module),
+	}
+}
+
+// inApp reports whether pkg is module or a package below it.
+func inApp(pkg, module string) bool {
+	return module != "" && (pkg == module || strings.HasPrefix(pkg, module+"/"))
+}
+
+// splitFuncName splits a fully qualified function name like
+// "github.com/ihleven/errors.(*withStack).Format" into package and function.
+func splitFuncName(name string) (pkg string, function string) {
+	slash := strings.LastIndex(name, "/") + 1
+	dot := strings.Index(name[slash:], ".")
+	if dot < 0 {
+		return "", name
+	}
+	return name[:slash+dot], name[slash+dot+1:]
+}
+
+// typeName returns the registered name of the layer's code or the type of its error.
+// The internal types of package errors are reported as "error", as Sentry groups by type.
+func typeName(layer errors.Layer) string {
+	if layer.Code != errors.NoCode {
+		if info, ok := errors.LookupCode(layer.Code); ok && info.Name != "" {
+			return info.Name
+		}
+		return "Code" + strconv.Itoa(int(layer.Code))
+	}
+	t := reflect.TypeOf(layer.Err)
+	if t.Kind() == reflect.Pointer && t.Elem().PkgPath() == errorsPath || t.PkgPath() == errorsPath {
+		return "error"
+	}
+	return t.String()
+}
+
+// errorsPath is the import path of package errors.
+var errorsPath = reflect.TypeOf(errors.NoCode).PkgPath()
+
+// maxTagLength is the maximum length of tag values accepted by Sentry.
+const maxTagLength = 200
+
+func tags(err error) map[string]string {
+
+	tags := make(map[string]string)
+
+	if code := errors.ErrorCode(errors.Code(err)); code != errors.NoCode {
+		tags["code"] = strconv.Itoa(int(code))
+		if info, ok := errors.LookupCode(code); ok {
+			if info.Name != "" {
+				tags["code.name"] = info.Name
+			}
+			if info.Category != "" {
+				tags["code.category"] = info.Category
+			}
+		}
+	}
+	for key, value := range errors.Fields(err) {
+		tags[key] = truncate(fmt.Sprint(value), maxTagLength)
+	}
+	return tags
+}
+
+// truncate cuts s to at most n bytes without splitting a UTF-8 encoded character.
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
+
+func level(s errors.Severity) string {
+	switch s {
+	case errors.SeverityDebug:
+		return "debug"
+	case errors.SeverityInfo:
+		return "info"
+	case errors.SeverityWarn:
+		return "warning"
+	case errors.SeverityCritical:
+		return "fatal"
+	}
+	return "error"
+}
+
+func newEventID() string {
+	var id [16]byte
+	rand.Read(id[:])
+	return hex.EncodeToString(id[:])
+}
+
+// mainModule returns the path of the main module of the running binary, if known.
+func mainModule() string {
+	if info, ok := debug.ReadBuildInfo(); ok {
+		return info.Main.Path
+	}
+	return ""
+}
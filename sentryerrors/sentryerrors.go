@@ -0,0 +1,164 @@
+// Package sentryerrors reports errors of github.com/ihleven/errors to Sentry.
+//
+// Errors are converted into Sentry events with an exception per layer of the error chain,
+// see NewEvent, and sent through a Transport, by default HTTP to the DSN of a Sentry project:
+//
+//	client, err := sentryerrors.NewClient("https://public@sentry.example.com/42")
+//	...
+//	client.Capture(ctx, err)
+package sentryerrors
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Transport sends events to Sentry.
+type Transport interface {
+	Send(ctx context.Context, event *Event) error
+}
+
+// Client captures errors as Sentry events.
+type Client struct {
+	// Transport sends the events.
+	Transport Transport
+	// Module is the module path below which frames are considered in app.
+	// NewClient sets it to the main module of the running binary.
+	Module string
+	// Release and Environment are attached to every event if set.
+	Release     string
+	Environment string
+}
+
+// NewClient returns a client sending events over HTTP to the project identified by dsn.
+func NewClient(dsn string) (*Client, error) {
+
+	transport, err := NewHTTPTransport(dsn)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{Transport: transport, Module: mainModule()}, nil
+}
+
+// Capture sends err as an event and returns the event's ID. It does nothing if err is nil.
+func (c *Client) Capture(ctx context.Context, err error) (string, error) {
+
+	if err == nil {
+		return "", nil
+	}
+
+	event := NewEvent(err, c.Module)
+	event.Release = c.Release
+	event.Environment = c.Environment
+
+	if err := c.Transport.Send(ctx, event); err != nil {
+		return "", err
+	}
+	return event.EventID, nil
+}
+
+// HTTPTransport sends events as envelopes to the envelope endpoint of a Sentry project.
+type HTTPTransport struct {
+	// Client is the HTTP client used, http.DefaultClient if nil.
+	Client *http.Client
+
+	dsn      string
+	endpoint string
+	auth     string
+}
+
+// NewHTTPTransport returns a transport for the project identified by dsn,
+// which is of the form "https://<public key>@<host>/<project id>".
+func NewHTTPTransport(dsn string) (*HTTPTransport, error) {
+
+	u, err := url.Parse(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("sentryerrors: invalid DSN: %w", err)
+	}
+	if u.User == nil || u.User.Username() == "" {
+		return nil, fmt.Errorf("sentryerrors: invalid DSN %q: missing public key", dsn)
+	}
+
+	i := strings.LastIndex(u.Path, "/")
+	project := u.Path[i+1:]
+	if project == "" {
+		return nil, fmt.Errorf("sentryerrors: invalid DSN %q: missing project id", dsn)
+	}
+
+	endpoint := url.URL{
+		Scheme: u.Scheme,
+		Host:   u.Host,
+		Path:   u.Path[:i] + "/api/" + project + "/envelope/",
+	}
+
+	return &HTTPTransport{
+		dsn:      dsn,
+		endpoint: endpoint.String(),
+		auth:     "Sentry sentry_version=7, sentry_client=ihleven-errors/1.0, sentry_key=" + u.User.Username(),
+	}, nil
+}
+
+// Send implements Transport.
+func (t *HTTPTransport) Send(ctx context.Context, event *Event) error {
+
+	body, err := envelope(t.dsn, event)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-sentry-envelope")
+	req.Header.Set("X-Sentry-Auth", t.auth)
+
+	client := t.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("sentryerrors: sending event: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("sentryerrors: sending event: %s", resp.Status)
+	}
+	return nil
+}
+
+// envelope encodes event as an envelope with a single event item.
+// See https://develop.sentry.dev/sdk/envelopes/.
+func envelope(dsn string, event *Event) ([]byte, error) {
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.Encode(map[string]interface{}{
+		"event_id": event.EventID,
+		"dsn":      dsn,
+		"sent_at":  time.Now().UTC(),
+	})
+	enc.Encode(map[string]interface{}{
+		"type":   "event",
+		"length": len(payload),
+	})
+	buf.Write(payload)
+	buf.WriteByte('\n')
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,151 @@
+package sentryerrors_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/ihleven/errors"
+	"github.com/ihleven/errors/sentryerrors"
+)
+
+func findUser() error {
+	return errors.NewWithCode(errors.NotFound, "no such user", errors.Field("user", "bob"))
+}
+
+func TestCapture(t *testing.T) {
+
+	var auth, contentType string
+	var lines []string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/42/envelope/" {
+			http.NotFound(w, r)
+			return
+		}
+		auth, contentType = r.Header.Get("X-Sentry-Auth"), r.Header.Get("Content-Type")
+		scanner := bufio.NewScanner(r.Body)
+		for scanner.Scan() {
+			lines = append(lines, scanner.Text())
+		}
+	}))
+	defer server.Close()
+
+	client, err := sentryerrors.NewClient(strings.Replace(server.URL, "http://", "http://public@", 1) + "/42")
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.Module = "github.com/ihleven/errors"
+	client.Environment = "test"
+
+	id, err := client.Capture(context.Background(), errors.Wrap(findUser(), "loading profile"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(auth, "sentry_key=public") || contentType != "application/x-sentry-envelope" {
+		t.Errorf("X-Sentry-Auth = %q, Content-Type = %q", auth, contentType)
+	}
+	if len(lines) != 3 {
+		t.Fatalf("envelope has %d lines, want 3", len(lines))
+	}
+
+	var event sentryerrors.Event
+	if err := json.Unmarshal([]byte(lines[2]), &event); err != nil {
+		t.Fatal(err)
+	}
+	if event.EventID != id || event.Level != "debug" || event.Environment != "test" {
+		t.Errorf("event = %+v", event)
+	}
+	if event.Tags["code"] != "404" || event.Tags["code.name"] != "NotFound" || event.Tags["user"] != "bob" {
+		t.Errorf("tags = %v", event.Tags)
+	}
+
+	values := event.Exception.Values
+	if len(values) != 2 {
+		t.Fatalf("got %d exceptions, want 2", len(values))
+	}
+	if values[0].Type != "NotFound" || values[0].Value != "no such user" || values[1].Type != "error" || values[1].Value != "loading profile" {
+		t.Errorf("exceptions = %+v", values)
+	}
+
+	if errors.GetStackTrace(errors.New("probe")) == nil {
+		// stack traces are disabled by the build tag errors_nostack
+		if values[0].Stacktrace != nil {
+			t.Errorf("stacktrace = %+v, want none", values[0].Stacktrace)
+		}
+		return
+	}
+	frames := values[0].Stacktrace.Frames
+	last := frames[len(frames)-1]
+	if last.Function != "findUser" || last.Module != "github.com/ihleven/errors/sentryerrors_test" || !last.InApp {
+		t.Errorf("newest frame = %+v, want findUser in app", last)
+	}
+	if first := frames[0]; first.InApp {
+		t.Errorf("oldest frame = %+v, want not in app", first)
+	}
+
+	if frames := values[1].Stacktrace.Frames; len(frames) != 1 || frames[0].Function != "TestCapture" ||
+		frames[0].Module != "github.com/ihleven/errors/sentryerrors_test" || !frames[0].InApp {
+		t.Errorf("wrap frames = %+v, want TestCapture", frames)
+	}
+}
+
+func TestNewEvent(t *testing.T) {
+
+	err := errors.Wrap(errors.New("no such user", errors.Field("name", strings.Repeat("ä", 150))), "loading profile")
+
+	event := sentryerrors.NewEvent(err, "example.com/other")
+	if name := event.Tags["name"]; len(name) > 200 || !utf8.ValidString(name) || name != strings.Repeat("ä", 100) {
+		t.Errorf("tag name = %q, want 100 runes of ä", name)
+	}
+	for _, ex := range event.Exception.Values {
+		if ex.Type != "error" {
+			t.Errorf("exception type = %q, want error for the internal types of package errors", ex.Type)
+		}
+	}
+	if got := sentryerrors.NewEvent(fmt.Errorf("ctx: %w", err), "").Exception.Values[2].Type; got != "*fmt.wrapError" {
+		t.Errorf("exception type = %q, want the type of the foreign error", got)
+	}
+
+	if errors.GetStackTrace(errors.New("probe")) == nil {
+		t.Skip("stack traces disabled by the build tag errors_nostack")
+	}
+	for _, ex := range event.Exception.Values {
+		for _, f := range ex.Stacktrace.Frames {
+			if f.InApp {
+				t.Errorf("frame %+v in app, want none for another module", f)
+			}
+		}
+	}
+}
+
+func TestCaptureFails(t *testing.T) {
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "rate limited", http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	client, err := sentryerrors.NewClient(strings.Replace(server.URL, "http://", "http://public@", 1) + "/42")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Capture(context.Background(), errors.New("boom")); err == nil {
+		t.Errorf("Capture() = nil, want error")
+	}
+}
+
+func TestNewClientInvalidDSN(t *testing.T) {
+	for _, dsn := range []string{"https://sentry.example.com/42", "https://public@sentry.example.com/", ":"} {
+		if _, err := sentryerrors.NewClient(dsn); err == nil {
+			t.Errorf("NewClient(%q) = nil error, want error", dsn)
+		}
+	}
+}
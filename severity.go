@@ -0,0 +1,96 @@
+package errors
+
+import (
+	"log/slog"
+	"strconv"
+)
+
+// Severity classifies how serious an error is, e.g. to decide at which level it is logged.
+// Expected errors like a missing resource may be logged at SeverityDebug while others
+// are worth paging someone with SeverityCritical.
+type Severity int
+
+// Severity levels in increasing order. The zero value means no severity was set.
+const (
+	SeverityUnset Severity = iota
+	SeverityDebug
+	SeverityInfo
+	SeverityWarn
+	SeverityError
+	SeverityCritical
+)
+
+// LevelCritical is the slog level SeverityCritical maps to.
+const LevelCritical = slog.LevelError + 4
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityUnset:
+		return "unset"
+	case SeverityDebug:
+		return "debug"
+	case SeverityInfo:
+		return "info"
+	case SeverityWarn:
+		return "warn"
+	case SeverityError:
+		return "error"
+	case SeverityCritical:
+		return "critical"
+	}
+	return "severity(" + strconv.Itoa(int(s)) + ")"
+}
+
+// Level maps the severity to a slog.Level, so that a Severity can be used as slog.Leveler.
+// SeverityCritical maps to LevelCritical, unset or unknown severities to slog.LevelError.
+func (s Severity) Level() slog.Level {
+	switch s {
+	case SeverityDebug:
+		return slog.LevelDebug
+	case SeverityInfo:
+		return slog.LevelInfo
+	case SeverityWarn:
+		return slog.LevelWarn
+	case SeverityCritical:
+		return LevelCritical
+	}
+	return slog.LevelError
+}
+
+// GetSeverity returns the severity of err. It is resolved along err's chain as follows:
+//
+//  1. the outermost severity attached with WithSeverity,
+//  2. the severity registered for the code of err (see RegisterCode),
+//  3. SeverityError.
+//
+// GetSeverity returns SeverityUnset if err is nil.
+func GetSeverity(err error) Severity {
+
+	if err == nil {
+		return SeverityUnset
+	}
+
+	severity := SeverityUnset
+
+	Walk(err, func(e error) bool {
+		if m, ok := e.(interface{ meta() *metadata }); ok && m.meta().severity != SeverityUnset {
+			severity = m.meta().severity
+			return false
+		}
+		return true
+	})
+	if severity != SeverityUnset {
+		return severity
+	}
+
+	if info, ok := LookupCode(ErrorCode(Code(err))); ok && info.Severity != SeverityUnset {
+		return info.Severity
+	}
+	return SeverityError
+}
+
+// LogLevel returns the slog.Level err should be logged at, which is GetSeverity(err).Level().
+// Logging middleware can use it to log expected errors at a lower level.
+func LogLevel(err error) slog.Level {
+	return GetSeverity(err).Level()
+}
@@ -0,0 +1,44 @@
+package errors_test
+
+import (
+	"fmt"
+	"log/slog"
+	"testing"
+
+	"github.com/ihleven/errors"
+)
+
+func TestGetSeverity(t *testing.T) {
+
+	const Throttled errors.ErrorCode = 429
+	errors.RegisterCode(Throttled, errors.CodeInfo{Name: "Throttled", Severity: errors.SeverityWarn})
+
+	tests := []struct {
+		name string
+		err  error
+		want errors.Severity
+	}{
+		{"nil", nil, errors.SeverityUnset},
+		{"no code", errors.New("boom"), errors.SeverityError},
+		{"foreign", fmt.Errorf("boom"), errors.SeverityError},
+		{"builtin default", errors.NewWithCode(errors.NotFound, "missing"), errors.SeverityDebug},
+		{"registered default", errors.Wrap(errors.NewWithCode(Throttled, "slow down"), "calling api"), errors.SeverityWarn},
+		{"explicit", errors.NewWithCode(errors.NotFound, "missing", errors.WithSeverity(errors.SeverityCritical)), errors.SeverityCritical},
+		{"outermost wins", errors.Wrap(errors.New("boom", errors.WithSeverity(errors.SeverityCritical)), "ignored", errors.WithSeverity(errors.SeverityInfo)), errors.SeverityInfo},
+	}
+	for _, tt := range tests {
+		if got := errors.GetSeverity(tt.err); got != tt.want {
+			t.Errorf("%s: GetSeverity() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestLogLevel(t *testing.T) {
+
+	if got := errors.LogLevel(errors.NewWithCode(errors.NotFound, "missing")); got != slog.LevelDebug {
+		t.Errorf("LogLevel(NotFound) = %v, want %v", got, slog.LevelDebug)
+	}
+	if got := errors.LogLevel(errors.New("boom", errors.WithSeverity(errors.SeverityCritical))); got != errors.LevelCritical {
+		t.Errorf("LogLevel(critical) = %v, want %v", got, errors.LevelCritical)
+	}
+}
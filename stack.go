@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"io"
 	"path"
+	"reflect"
 	"runtime"
 	"strconv"
 	"strings"
@@ -137,41 +138,270 @@ func (st StackTrace) formatSlice(s fmt.State, verb rune) {
 }
 
 // stack represents a stack of program counters.
-type stack []uintptr
+// An empty stack is valid and formats as nothing, see StackMode.
+type stack struct {
+	pcs     []uintptr
+	more    int    // number of frames beyond the recorded ones
+	created *stack // stack of the goroutine which launched the recording one with Go, if any
+}
 
-func (s *stack) Format(st fmt.State, verb rune) {
+func (s stack) Format(st fmt.State, verb rune) {
 	switch verb {
 	case 'v':
 		switch {
 		case st.Flag('+'):
-			for _, pc := range *s {
+			for _, pc := range s.pcs {
 				f := Frame(pc)
 				fmt.Fprintf(st, "\n%+v", f)
 			}
+			s.formatMore(st)
+			s.formatCreated(st)
 
 		case st.Flag('#'):
-			for _, pc := range *s {
+			for _, pc := range s.pcs {
 				f := Frame(pc)
 				fmt.Fprintf(st, "\n%+v", f)
 			}
+			s.formatMore(st)
+			s.formatCreated(st)
 		}
 	}
 }
 
-func (s *stack) StackTrace() StackTrace {
-	f := make([]Frame, len(*s))
-	for i := 0; i < len(f); i++ {
-		f[i] = Frame((*s)[i])
+// formatMore writes a marker for the frames beyond the recorded ones, if any.
+func (s stack) formatMore(st fmt.State) {
+	if s.more > 0 {
+		fmt.Fprintf(st, "\n%s", moreFrames(s.more))
+	}
+}
+
+// formatCreated writes the stacks of the goroutines which launched the recording one, if any.
+func (s stack) formatCreated(st fmt.State) {
+	if s.created != nil {
+		io.WriteString(st, "\ncreated by")
+		s.created.Format(st, 'v')
+	}
+}
+
+// moreFrames returns the marker for n frames beyond the recorded ones.
+func moreFrames(n int) string {
+	return "... " + strconv.Itoa(n) + " more frames"
+}
+
+func (s stack) StackTrace() StackTrace {
+	return frames(s.pcs)
+}
+
+// location returns file, short function name and line of the innermost frame, if any.
+func (s stack) location() (file string, function string, line int) {
+	if len(s.pcs) == 0 {
+		return "", "", 0
 	}
-	return f
+	return location(Frame(s.pcs[0]))
 }
 
-func callers() *stack {
-	const depth = 32
-	var pcs [depth]uintptr
-	n := runtime.Callers(3, pcs[:])
-	var st stack = pcs[0:n]
-	return &st
+// formatUnique formats the stack like Format with the flag '+', but elides the outermost
+// frames it has in common with another stack printed already, like the stack of an
+// error joined with this one or recorded further down the chain.
+func (s stack) formatUnique(st fmt.State, inner []uintptr) {
+
+	common := 0
+	if s.more == 0 {
+		for common < len(s.pcs) && common < len(inner) &&
+			s.pcs[len(s.pcs)-1-common] == inner[len(inner)-1-common] {
+			common++
+		}
+	}
+
+	for _, pc := range s.pcs[:len(s.pcs)-common] {
+		fmt.Fprintf(st, "\n%+v", Frame(pc))
+	}
+	if common > 0 {
+		fmt.Fprintf(st, "\n... %d common frames elided", common)
+	}
+	s.formatMore(st)
+	s.formatCreated(st)
+}
+
+// StackTracer is implemented by errors carrying a stack trace, like the errors created
+// by New and those Wrap attached a stack trace to. StackTrace returns the frames recorded
+// when the error was created, innermost first. The stack of errors of other packages
+// implementing StackTracer is honoured by Wrap, which does not record another one,
+// GetStackTrace, Layers and the %+v format.
+type StackTracer interface {
+	StackTrace() StackTrace
+}
+
+// GetStackTrace returns the deepest stack trace of err's chain, i.e. the one recorded
+// closest to where the error originated. The chain is traversed as by Walk.
+// It returns nil if no error of the chain carries a stack trace.
+func GetStackTrace(err error) StackTrace {
+
+	var pcs []uintptr
+	Walk(err, func(e error) bool {
+		if inner, ok := stackOf(e); ok {
+			pcs = inner
+		}
+		return true
+	})
+	return frames(pcs)
+}
+
+// frames converts program counters to a StackTrace.
+func frames(pcs []uintptr) StackTrace {
+	if len(pcs) == 0 {
+		return nil
+	}
+	st := make([]Frame, len(pcs))
+	for i, pc := range pcs {
+		st[i] = Frame(pc)
+	}
+	return st
+}
+
+// stackOf returns the program counters of the stack trace carried by err itself.
+// Besides StackTracer, stack traces of github.com/pkg/errors and packages following its
+// conventions, i.e. a StackTrace method returning a slice of uintptr based frames, are
+// recognized.
+func stackOf(err error) ([]uintptr, bool) {
+
+	switch x := err.(type) {
+	case *fundamental:
+		return x.stack.pcs, true
+	case *withStack:
+		return x.stack.pcs, true
+	case StackTracer:
+		st := x.StackTrace()
+		pcs := make([]uintptr, len(st))
+		for i, f := range st {
+			pcs[i] = uintptr(f)
+		}
+		return pcs, true
+	}
+
+	m := reflect.ValueOf(err).MethodByName("StackTrace")
+	if !m.IsValid() {
+		return nil, false
+	}
+	t := m.Type()
+	if t.NumIn() != 0 || t.NumOut() != 1 || t.Out(0).Kind() != reflect.Slice || t.Out(0).Elem().Kind() != reflect.Uintptr {
+		return nil, false
+	}
+	st := m.Call(nil)[0]
+	pcs := make([]uintptr, st.Len())
+	for i := range pcs {
+		pcs[i] = uintptr(st.Index(i).Uint())
+	}
+	return pcs, true
+}
+
+// innerStack returns the stack trace of the outermost error in err's chain carrying one.
+func innerStack(err error) (pcs []uintptr, ok bool) {
+	Walk(err, func(e error) bool {
+		pcs, ok = stackOf(e)
+		return !ok
+	})
+	return pcs, ok
+}
+
+// chainStacks returns the outermost stack of each branch of err's chain, i.e. the stacks
+// of the causes of errors.Join or of errors joined by fmt.Errorf with several %w verbs.
+// The branches are not followed beyond the first error carrying a stack, as its own
+// formatting covers the rest of the branch.
+func chainStacks(err error) []stack {
+
+	var stacks []stack
+	seen := make(map[error]bool)
+	var visit func(error)
+	visit = func(err error) {
+		if hashable(err) {
+			if seen[err] {
+				return
+			}
+			seen[err] = true
+		}
+		switch x := err.(type) {
+		case *fundamental:
+			stacks = append(stacks, x.stack)
+			return
+		case *withStack:
+			stacks = append(stacks, x.stack)
+			return
+		}
+		if pcs, ok := stackOf(err); ok {
+			stacks = append(stacks, stack{pcs: pcs})
+			return
+		}
+		for _, e := range causes(err) {
+			visit(e)
+		}
+	}
+	visit(err)
+	return stacks
+}
+
+// initialDepth is the number of frames recorded without allocating,
+// deeper stacks are recorded up to the depth set by SetMaxStackDepth.
+const initialDepth = 32
+
+// callers records the stack of the caller of the function calling callers and returns
+// its program counters together with the number of frames beyond the maximum depth.
+// skip is the number of additional frames to skip. Stacks up to initialDepth are
+// recorded into buf. How much is recorded depends on the StackMode, nothing is
+// recorded for StackNone.
+func callers(skip int, buf *[initialDepth]uintptr) (pcs []uintptr, more int) {
+
+	if stackDisabled {
+		return nil, 0
+	}
+
+	mode := StackMode(stackMode.Load())
+	if mode == StackNone {
+		return nil, 0
+	}
+
+	if mode == StackCaller || stackSampling.Load() > 1 {
+		n := runtime.Callers(3+skip, buf[:1])
+		if mode == StackCaller || n == 0 || !sampled(buf[0]) {
+			return buf[:n], 0
+		}
+	}
+
+	max := int(maxStackDepth.Load())
+	if max == 0 {
+		max = DefaultMaxStackDepth
+	}
+
+	pcs = buf[:]
+	n := runtime.Callers(3+skip, pcs)
+	for n == len(pcs) && len(pcs) < max {
+		pcs = make([]uintptr, min(2*len(pcs), max))
+		n = runtime.Callers(3+skip, pcs)
+	}
+
+	if n == len(pcs) {
+		// the buffer is full, count the frames not recorded
+		var scratch [initialDepth]uintptr
+		for m := len(scratch); m == len(scratch); {
+			m = runtime.Callers(3+skip+n+more, scratch[:])
+			more += m
+		}
+	}
+	if n > max {
+		more += n - max
+		n = max
+	}
+	return pcs[:n], more
+}
+
+// caller returns the frame of the caller of the function calling caller.
+// skip is the number of additional frames to skip.
+func caller(skip int) Frame {
+	var pc [1]uintptr
+	if runtime.Callers(3+skip, pc[:]) == 0 {
+		return 0
+	}
+	return Frame(pc[0])
 }
 
 // funcname removes the path prefix component of a function's name reported by func.Name().
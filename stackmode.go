@@ -0,0 +1,71 @@
+package errors
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// StackMode controls how much of the stack New, NewWithCode and Wrap record.
+// Recording the stack is the most expensive part of creating an error, which matters
+// where errors are used for control flow in hot paths.
+type StackMode int32
+
+const (
+	// StackFull records the full stack. This is the default.
+	StackFull StackMode = iota
+	// StackCaller records only the immediate caller, enough to know where an error was created.
+	StackCaller
+	// StackNone records no stack at all.
+	StackNone
+)
+
+// DefaultMaxStackDepth is the default maximum number of frames recorded.
+const DefaultMaxStackDepth = 256
+
+var (
+	maxStackDepth atomic.Int32 // zero means DefaultMaxStackDepth
+	stackMode     atomic.Int32
+	stackSampling atomic.Uint64
+	sampleCounts  sync.Map // call site PC -> *atomic.Uint64
+)
+
+// SetStackMode sets how much of the stack is recorded from now on.
+// Building with the tag errors_nostack disables recording stacks regardless of the mode.
+func SetStackMode(mode StackMode) {
+	stackMode.Store(int32(mode))
+}
+
+// SetMaxStackDepth sets the maximum number of frames recorded. Frames beyond are counted
+// and reported as "... N more frames" when formatting the stack. Values below 1 restore
+// DefaultMaxStackDepth.
+func SetMaxStackDepth(depth int) {
+	if depth < 1 {
+		depth = 0
+	}
+	maxStackDepth.Store(int32(depth))
+}
+
+// SetStackSampling makes StackFull record the full stack for only one in n errors
+// created at the same call site, starting with the first. For the others only the
+// immediate caller is recorded. n <= 1 records the full stack every time.
+func SetStackSampling(n int) {
+	if n < 1 {
+		n = 1
+	}
+	stackSampling.Store(uint64(n))
+}
+
+// sampled reports whether the full stack should be recorded at the call site pc.
+func sampled(pc uintptr) bool {
+
+	n := stackSampling.Load()
+	if n <= 1 {
+		return true
+	}
+
+	count, ok := sampleCounts.Load(pc)
+	if !ok {
+		count, _ = sampleCounts.LoadOrStore(pc, new(atomic.Uint64))
+	}
+	return count.(*atomic.Uint64).Add(1)%n == 1
+}
@@ -0,0 +1,155 @@
+//go:build !errors_nostack
+
+package errors_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/ihleven/errors"
+)
+
+func setStackMode(t *testing.T, mode errors.StackMode, sampling int) {
+	errors.SetStackMode(mode)
+	errors.SetStackSampling(sampling)
+	t.Cleanup(func() {
+		errors.SetStackMode(errors.StackFull)
+		errors.SetStackSampling(1)
+	})
+}
+
+func stackDepth(err error) int {
+	layers := errors.Layers(err)
+	return len(layers[len(layers)-1].Stack)
+}
+
+func TestStackModes(t *testing.T) {
+
+	setStackMode(t, errors.StackCaller, 1)
+	err := errors.New("boom")
+	if n := stackDepth(err); n != 1 {
+		t.Errorf("StackCaller recorded %d frames, want 1", n)
+	}
+	if layer := errors.Layers(err)[0]; layer.Function != "TestStackModes" {
+		t.Errorf("StackCaller location = %s, want TestStackModes", layer.Function)
+	}
+
+	errors.SetStackMode(errors.StackNone)
+	err = errors.Wrap(errors.Wrap(fmt.Errorf("foreign"), "wrapped"))
+	if n := stackDepth(err); n != 0 {
+		t.Errorf("StackNone recorded %d frames, want 0", n)
+	}
+	if got, want := fmt.Sprintf("%+v", err), "wrapped"; !strings.HasPrefix(got, want) {
+		t.Errorf("%%+v without stack = %q, want prefix %q", got, want)
+	}
+	if got := fmt.Sprintf("%+v", errors.New("boom")); got != "boom" {
+		t.Errorf("%%+v without stack = %q, want %q", got, "boom")
+	}
+}
+
+func TestStackSampling(t *testing.T) {
+
+	setStackMode(t, errors.StackFull, 3)
+
+	var depths []int
+	for i := 0; i < 6; i++ {
+		depths = append(depths, stackDepth(errors.New("boom")))
+	}
+	for i, n := range depths {
+		if sampled := i%3 == 0; sampled != (n > 1) || n == 0 {
+			t.Errorf("error %d recorded %d frames, sampled = %v", i, n, sampled)
+		}
+	}
+}
+
+func recurse(depth int, fn func() error) error {
+	if depth == 0 {
+		return fn()
+	}
+	return recurse(depth-1, fn)
+}
+
+func TestDeepStacks(t *testing.T) {
+
+	newError := func() error { return errors.New("deep") }
+	wrapError := func() error { return errors.Wrap(fmt.Errorf("deep")) }
+
+	for name, fn := range map[string]func() error{"New": newError, "Wrap": wrapError} {
+		full := errors.Layers(recurse(100, fn))
+		if n := len(full[len(full)-1].Stack); n < 100 {
+			t.Errorf("%s: recorded %d frames of a stack deeper than 100", name, n)
+		}
+
+		errors.SetMaxStackDepth(40)
+		err := recurse(100, fn)
+		errors.SetMaxStackDepth(0)
+
+		layers := errors.Layers(err)
+		layer := layers[len(layers)-1]
+		if len(layer.Stack) != 40 || layer.More != len(full[len(full)-1].Stack)-40 {
+			t.Errorf("%s: recorded %d frames and %d more, want 40 and %d more", name, len(layer.Stack), layer.More, len(full[len(full)-1].Stack)-40)
+		}
+
+		marker := fmt.Sprintf("\n... %d more frames", layer.More)
+		if text := fmt.Sprintf("%+v", err); !strings.HasSuffix(text, marker) {
+			t.Errorf("%s: %%+v does not end with %q", name, marker)
+		}
+		if b, _ := json.Marshal(layer); !strings.Contains(string(b), fmt.Sprintf(`"... %d more frames"]`, layer.More)) {
+			t.Errorf("%s: JSON %s lacks more frames marker", name, b)
+		}
+	}
+}
+
+// formatter is a foreign error printing the stacks of its cause with %+v.
+type formatter struct{ cause error }
+
+func (f *formatter) Error() string { return "formatter: " + f.cause.Error() }
+func (f *formatter) Unwrap() error { return f.cause }
+func (f *formatter) Format(s fmt.State, verb rune) {
+	if verb == 'v' && s.Flag('+') {
+		fmt.Fprintf(s, "formatter: %+v", f.cause)
+		return
+	}
+	io.WriteString(s, f.Error())
+}
+
+// collector is a foreign error collecting errors after it has been wrapped.
+type collector struct{ errs []error }
+
+func (c *collector) Error() string   { return fmt.Sprint(c.errs) }
+func (c *collector) Unwrap() []error { return c.errs }
+func (c *collector) Format(s fmt.State, verb rune) {
+	for _, err := range c.errs {
+		fmt.Fprintf(s, "%+v", err)
+	}
+}
+
+func TestOverlappingStacks(t *testing.T) {
+
+	inner := errors.Wrap(fmt.Errorf("foreign"))
+	err := errors.Wrap(fmt.Errorf("passing through: %w", &formatter{inner}), "wrapped")
+	if n := strings.Count(fmt.Sprintf("%+v", err), "TestOverlappingStacks"); n != 2 {
+		t.Errorf("%%+v prints the test function %d times, want a single stack and the wrap location", n)
+	}
+	if _, ok := errors.Layers(err)[1].Err.(interface{ StackTrace() errors.StackTrace }); ok {
+		t.Errorf("Wrap attached a stack although one is contained in the chain")
+	}
+
+	c := &collector{}
+	err = recurse(3, func() error { return errors.Wrap(c) })
+	c.errs = append(c.errs, errors.New("collected"))
+
+	text := fmt.Sprintf("%+v", err)
+	if n := strings.Count(text, "testing.tRunner"); n != 1 {
+		t.Errorf("%%+v prints testing.tRunner %d times, want once", n)
+	}
+	if !strings.Contains(text, "common frames elided") {
+		t.Errorf("%%+v lacks common frames marker:\n%s", text)
+	}
+	if n := strings.Count(text, "errors_test.recurse"); n != 4 {
+		t.Errorf("%%+v prints recurse %d times, want the 4 frames unique to the outer stack", n)
+	}
+}
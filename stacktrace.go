@@ -68,25 +68,6 @@ var cleanPath = RemoveGoPath
 
 // 	return err
 // }
-func caller() (file string, function string, line int, ok bool) {
-
-	pc, file, line, ok := runtime.Caller(2)
-	if !ok {
-		return
-	}
-	if cleanPath != nil {
-		file = cleanPath(file)
-	}
-
-	f := runtime.FuncForPC(pc)
-	if f == nil {
-		return
-	}
-	function = shortFuncName(f)
-
-	return
-}
-
 /* "FuncName" or "Receiver.MethodName" */
 func shortFuncName(f *runtime.Func) string {
 	// f.Name() is like one of these:
@@ -0,0 +1,114 @@
+// Package symbolize resolves the program counters of stack traces encoded by package
+// errors with the stack encoding EncodePCs into functions, files and lines, using the
+// ELF executable they were recorded in:
+//
+//	bin, err := symbolize.Open("./server")
+//	...
+//	frames, err := bin.Symbolize(layer.Build, pcs)
+//
+// Calls inlined by the compiler are attributed to the function they were inlined into.
+// Only ELF executables are supported, Open fails for other formats like Mach-O or PE.
+package symbolize
+
+import (
+	"debug/elf"
+	"debug/gosym"
+	stderrors "errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ihleven/errors"
+	"github.com/ihleven/errors/buildid"
+)
+
+// ErrBuildMismatch is returned by Symbolize for program counters recorded in another build.
+var ErrBuildMismatch = stderrors.New("symbolize: build ID does not match the executable")
+
+// Frame is a resolved program counter.
+type Frame struct {
+	Function string // fully qualified function name, "unknown" if not resolved
+	File     string // full path of the source file
+	Line     int    // line in the source file
+}
+
+// Binary is an executable program counters are resolved against.
+type Binary struct {
+	buildID string
+	table   *gosym.Table
+}
+
+// Open reads the symbol and line tables of the ELF executable name.
+func Open(name string) (*Binary, error) {
+
+	f, err := elf.Open(name)
+	if err != nil {
+		return nil, fmt.Errorf("symbolize: %w", err)
+	}
+	defer f.Close()
+
+	text, pclntab := f.Section(".text"), f.Section(".gopclntab")
+	if text == nil || pclntab == nil {
+		return nil, fmt.Errorf("symbolize: %s has no Go line table", name)
+	}
+	pcln, err := pclntab.Data()
+	if err != nil {
+		return nil, fmt.Errorf("symbolize: reading line table: %w", err)
+	}
+	var symbols []byte
+	if symtab := f.Section(".gosymtab"); symtab != nil {
+		if symbols, err = symtab.Data(); err != nil {
+			return nil, fmt.Errorf("symbolize: reading symbol table: %w", err)
+		}
+	}
+	table, err := gosym.NewTable(symbols, gosym.NewLineTable(pcln, text.Addr))
+	if err != nil {
+		return nil, fmt.Errorf("symbolize: %w", err)
+	}
+
+	id, _ := buildid.ReadELF(f)
+	return &Binary{buildID: id, table: table}, nil
+}
+
+// BuildID returns the Go build ID of the executable, empty if it has none.
+func (b *Binary) BuildID() string { return b.buildID }
+
+// Symbolize resolves the program counters pcs, recorded by a process described by build.
+// The pcs are the values of errors.Frame, i.e. return addresses as encoded with
+// EncodePCs. ErrBuildMismatch is returned if build names another executable.
+func (b *Binary) Symbolize(build errors.BuildInfo, pcs []uintptr) ([]Frame, error) {
+
+	if build.ID != "" && b.buildID != "" && build.ID != b.buildID {
+		return nil, ErrBuildMismatch
+	}
+
+	// offset the executable was loaded at, non-zero for position independent executables
+	var slide uintptr
+	if build.AnchorFunction != "" {
+		fn := b.table.LookupFunc(build.AnchorFunction)
+		if fn == nil {
+			return nil, fmt.Errorf("symbolize: anchor %s not found in the executable", build.AnchorFunction)
+		}
+		slide = build.Anchor - uintptr(fn.Entry)
+	}
+
+	frames := make([]Frame, len(pcs))
+	for i, pc := range pcs {
+		file, line, fn := b.table.PCToLine(uint64(pc - slide - 1))
+		if fn == nil {
+			frames[i] = Frame{Function: "unknown"}
+			continue
+		}
+		frames[i] = Frame{Function: fn.Name, File: file, Line: line}
+	}
+	return frames, nil
+}
+
+// ParsePC parses a program counter as encoded with EncodePCs, like "0x4a1b2f".
+func ParsePC(text string) (uintptr, bool) {
+	if !strings.HasPrefix(text, "0x") {
+		return 0, false
+	}
+	pc, err := strconv.ParseUint(text[2:], 16, 64)
+	return uintptr(pc), err == nil
+}
@@ -0,0 +1,115 @@
+//go:build !errors_nostack
+
+package symbolize_test
+
+import (
+	"debug/elf"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/ihleven/errors"
+	"github.com/ihleven/errors/symbolize"
+)
+
+//go:noinline
+func failing() error { return errors.New("failed") }
+
+func encodeRaw(t *testing.T, err error) (layer struct {
+	Stack []string          `json:"stack"`
+	Build *errors.BuildInfo `json:"build"`
+}) {
+	t.Helper()
+
+	errors.SetStackEncoding(errors.EncodePCs)
+	defer errors.SetStackEncoding(errors.EncodeSymbols)
+
+	b, e := json.Marshal(errors.Layers(err)[0])
+	if e != nil {
+		t.Fatal(e)
+	}
+	if e := json.Unmarshal(b, &layer); e != nil {
+		t.Fatal(e)
+	}
+	if layer.Build == nil {
+		t.Fatalf("JSON %s lacks build information", b)
+	}
+	return layer
+}
+
+func TestSymbolize(t *testing.T) {
+
+	exe := requireELF(t)
+	err := failing()
+	layer := encodeRaw(t, err)
+
+	var pcs []uintptr
+	for _, text := range layer.Stack {
+		pc, ok := symbolize.ParsePC(text)
+		if !ok {
+			t.Fatalf("stack holds %q, want program counters", text)
+		}
+		pcs = append(pcs, pc)
+	}
+
+	bin, e := symbolize.Open(exe)
+	if e != nil {
+		t.Fatal(e)
+	}
+	if bin.BuildID() == "" || bin.BuildID() != layer.Build.ID {
+		t.Errorf("build ID %q, recorded %q", bin.BuildID(), layer.Build.ID)
+	}
+
+	frames, e := bin.Symbolize(*layer.Build, pcs)
+	if e != nil {
+		t.Fatal(e)
+	}
+	want := errors.GetStackTrace(err)
+	if len(frames) != len(want) {
+		t.Fatalf("symbolized %d frames, want %d", len(frames), len(want))
+	}
+	for i, f := range frames {
+		text, _ := want[i].MarshalText()
+		if got := fmt.Sprintf("%s %s:%d", f.Function, f.File, f.Line); got != string(text) {
+			t.Errorf("frame %d = %s, want %s", i, got, text)
+		}
+	}
+
+	// a position independent executable loaded at another address
+	moved := *layer.Build
+	moved.Anchor += 0x10000
+	for i := range pcs {
+		pcs[i] += 0x10000
+	}
+	if slid, _ := bin.Symbolize(moved, pcs); len(slid) == 0 || slid[0] != frames[0] {
+		t.Errorf("symbolizing with a slide = %v, want %v", slid, frames)
+	}
+
+	moved.ID = "another build"
+	if _, e := bin.Symbolize(moved, pcs); e != symbolize.ErrBuildMismatch {
+		t.Errorf("symbolizing another build = %v, want ErrBuildMismatch", e)
+	}
+}
+
+// requireELF returns the path of the test binary and skips the test unless it is an
+// ELF executable, the only format supported.
+func requireELF(t *testing.T) string {
+	t.Helper()
+
+	exe, err := os.Executable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := elf.Open(exe)
+	var format *elf.FormatError
+	if stderrors.As(err, &format) {
+		t.Skipf("%s is no ELF executable", exe)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	return exe
+}
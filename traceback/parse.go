@@ -0,0 +1,133 @@
+package traceback
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	// goroutine 1 [running]:
+	// goroutine 1 gp=0xc000002380 m=0 mp=0x5c7e40 [running]:
+	headerRe = regexp.MustCompile(`^goroutine (\d+)(?: [^\[]*)? \[(.*)\]:$`)
+	// 	/path/to/file.go:12 +0x1d
+	// 	/path/to/file.go:12 +0x1d fp=0xc000045f58 sp=0xc000045f38 pc=0x46d9fd
+	locationRe = regexp.MustCompile(`^\t(.+?):(\d+)(?: \+0x([0-9a-f]+))?(?: .*)?$`)
+	// created by main.main in goroutine 1
+	createdRe = regexp.MustCompile(`^created by (.+?)(?: in goroutine (\d+))?$`)
+)
+
+// ErrNoGoroutine is returned by Parse for input not containing any goroutine.
+var ErrNoGoroutine = errors.New("traceback: no goroutine found")
+
+// Parse reads a traceback as printed by the runtime on a panic, a fatal error or SIGQUIT,
+// by debug.Stack or by runtime.Stack. Lines not belonging to the traceback, like other
+// output of a crashed process, are skipped. If several tracebacks are read, the goroutines
+// of all of them are returned along with the first panic message.
+func Parse(r io.Reader) (*Dump, error) {
+
+	p := parser{dump: &Dump{}}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1<<20)
+	for scanner.Scan() {
+		p.line(strings.TrimRight(scanner.Text(), "\r"))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("traceback: reading: %w", err)
+	}
+	p.end()
+
+	if len(p.dump.Goroutines) == 0 {
+		return nil, ErrNoGoroutine
+	}
+	return p.dump, nil
+}
+
+type parser struct {
+	dump    *Dump
+	panic   []string   // lines of the panic message being read
+	reading bool       // reading the panic message
+	g       *Goroutine // goroutine being read
+	frame   *Frame     // frame waiting for its location
+}
+
+func (p *parser) line(line string) {
+
+	if m := headerRe.FindStringSubmatch(line); m != nil {
+		p.end()
+		id, _ := strconv.Atoi(m[1])
+		p.g = &Goroutine{ID: id, State: m[2]}
+		return
+	}
+
+	if p.g == nil {
+		p.message(line)
+		return
+	}
+
+	if p.frame != nil {
+		if m := locationRe.FindStringSubmatch(line); m != nil {
+			p.frame.File = m[1]
+			p.frame.Line, _ = strconv.Atoi(m[2])
+			if m[3] != "" {
+				offset, _ := strconv.ParseUint(m[3], 16, 64)
+				p.frame.Offset = uintptr(offset)
+			}
+			p.frame = nil
+			return
+		}
+		p.frame = nil
+	}
+
+	switch {
+	case line == "...additional frames elided...":
+		p.g.Elided = true
+	case strings.HasPrefix(line, "created by "):
+		m := createdRe.FindStringSubmatch(line)
+		p.g.CreatedBy = &Frame{Function: m[1]}
+		p.g.Creator, _ = strconv.Atoi(m[2])
+		p.frame = p.g.CreatedBy
+	case strings.HasSuffix(line, ")") && !strings.HasPrefix(line, "\t") && strings.Contains(line, "("):
+		i := strings.LastIndex(line, "(")
+		p.g.Frames = append(p.g.Frames, Frame{Function: line[:i], Args: line[i+1 : len(line)-1]})
+		p.frame = &p.g.Frames[len(p.g.Frames)-1]
+	default:
+		// a blank line or anything else ends the goroutine
+		p.end()
+		p.message(line)
+	}
+}
+
+// message collects the lines of a panic message or fatal error preceding the goroutines.
+func (p *parser) message(line string) {
+
+	switch {
+	case p.dump.Panic != "":
+		// only the first message is kept
+	case strings.HasPrefix(line, "panic: "), strings.HasPrefix(line, "fatal error: "):
+		p.reading = true
+		p.panic = append(p.panic, line[strings.Index(line, ": ")+2:])
+	case p.reading && strings.TrimSpace(line) != "":
+		p.panic = append(p.panic, strings.TrimSpace(line))
+	default:
+		p.reading = false
+	}
+}
+
+// end finishes the goroutine and the panic message being read.
+func (p *parser) end() {
+
+	if p.g != nil {
+		p.dump.Goroutines = append(p.dump.Goroutines, *p.g)
+		p.g, p.frame = nil, nil
+	}
+	if p.dump.Panic == "" && len(p.panic) > 0 {
+		p.dump.Panic = strings.Join(p.panic, "\n")
+	}
+	p.panic, p.reading = nil, false
+}
@@ -0,0 +1,154 @@
+// Package traceback parses the tracebacks printed by the Go runtime, like the output of
+// a panic, a fatal error, a goroutine dump on SIGQUIT or debug.Stack, into goroutine
+// records, so that crash logs collected from other processes can be analyzed, fingerprinted
+// and rendered alike the stack traces of package errors.
+package traceback
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// Dump is a parsed traceback.
+type Dump struct {
+	Panic      string      // message of the panic or fatal error, lines joined by "\n", empty for plain dumps
+	Goroutines []Goroutine // goroutines in the order printed, the failing one first
+}
+
+// Goroutine is a goroutine of a traceback.
+type Goroutine struct {
+	ID        int     // goroutine id
+	State     string  // state as printed in brackets, e.g. "running" or "chan receive, 2 minutes"
+	Frames    []Frame // frames, innermost first
+	Elided    bool    // the runtime elided frames beyond the printed ones
+	CreatedBy *Frame  // go statement which launched the goroutine, if printed
+	Creator   int     // id of the goroutine which launched this one, if printed
+}
+
+// Frame is a frame of a goroutine's traceback.
+type Frame struct {
+	Function string  // fully qualified function name, e.g. "example.com/pkg.(*T).Method"
+	Args     string  // arguments as printed by the runtime, without parentheses
+	File     string  // full path of the source file
+	Line     int     // line in the source file
+	Offset   uintptr // offset of the program counter from the start of the function
+}
+
+// Name returns the function name without the package path, e.g. "(*T).Method".
+func (f Frame) Name() string {
+	name := f.Function[strings.LastIndex(f.Function, "/")+1:]
+	return name[strings.Index(name, ".")+1:]
+}
+
+// Package returns the import path of the package of the frame's function,
+// e.g. "example.com/pkg".
+func (f Frame) Package() string {
+	i := strings.LastIndex(f.Function, "/") + 1
+	if j := strings.Index(f.Function[i:], "."); j >= 0 {
+		return f.Function[:i+j]
+	}
+	return f.Function
+}
+
+// Format formats the frame like errors.Frame:
+//
+//	%s    source file
+//	%d    source line
+//	%n    function name
+//	%v    equivalent to %s:%d
+//
+// Format accepts flags that alter the printing of some verbs, as follows:
+//
+//	%+s   function name and path of source file separated by \n\t
+//	%+v   equivalent to %+s:%d
+func (f Frame) Format(s fmt.State, verb rune) {
+	switch verb {
+	case 's':
+		switch {
+		case s.Flag('+'):
+			io.WriteString(s, f.Function)
+			io.WriteString(s, "\n\t")
+			io.WriteString(s, f.File)
+		default:
+			io.WriteString(s, path.Base(f.File))
+		}
+	case 'd':
+		io.WriteString(s, strconv.Itoa(f.Line))
+	case 'n':
+		io.WriteString(s, f.Name())
+	case 'v':
+		f.Format(s, 's')
+		io.WriteString(s, ":")
+		f.Format(s, 'd')
+	}
+}
+
+// Fingerprint identifies the code path of the goroutine by the functions of its frames,
+// so that goroutines failing the same way share a fingerprint across builds, arguments
+// and line changes.
+func (g Goroutine) Fingerprint() string {
+	h := sha256.New()
+	for _, f := range g.Frames {
+		io.WriteString(h, f.Function)
+		io.WriteString(h, "\n")
+	}
+	return hex.EncodeToString(h.Sum(nil)[:8])
+}
+
+// Filter returns a copy of g with only the frames keep returns true for.
+func (g Goroutine) Filter(keep func(Frame) bool) Goroutine {
+	frames := make([]Frame, 0, len(g.Frames))
+	for _, f := range g.Frames {
+		if keep(f) {
+			frames = append(frames, f)
+		}
+	}
+	g.Frames = frames
+	return g
+}
+
+// Format formats the goroutine according to the fmt.Formatter interface.
+//
+//	%s    header of the goroutine, e.g. "goroutine 1 [running]"
+//	%v    see %s
+//
+// Format accepts flags that alter the printing of some verbs, as follows:
+//
+//	%+v   the goroutine as printed by the runtime, i.e. the header followed by
+//	      its frames and the go statement which launched it
+func (g Goroutine) Format(s fmt.State, verb rune) {
+	switch verb {
+	case 'v':
+		if s.Flag('+') {
+			fmt.Fprintf(s, "goroutine %d [%s]:", g.ID, g.State)
+			for _, f := range g.Frames {
+				fmt.Fprintf(s, "\n%s(%s)\n\t%s:%d", f.Function, f.Args, f.File, f.Line)
+				if f.Offset != 0 {
+					fmt.Fprintf(s, " +%#x", f.Offset)
+				}
+			}
+			if g.Elided {
+				io.WriteString(s, "\n...additional frames elided...")
+			}
+			if f := g.CreatedBy; f != nil {
+				fmt.Fprintf(s, "\ncreated by %s", f.Function)
+				if g.Creator != 0 {
+					fmt.Fprintf(s, " in goroutine %d", g.Creator)
+				}
+				fmt.Fprintf(s, "\n\t%s:%d", f.File, f.Line)
+				if f.Offset != 0 {
+					fmt.Fprintf(s, " +%#x", f.Offset)
+				}
+			}
+			return
+		}
+		fallthrough
+	case 's':
+		fmt.Fprintf(s, "goroutine %d [%s]", g.ID, g.State)
+	}
+}
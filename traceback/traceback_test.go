@@ -0,0 +1,183 @@
+package traceback_test
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"reflect"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"testing"
+
+	"github.com/ihleven/errors/traceback"
+)
+
+func parseFile(t *testing.T, name string) *traceback.Dump {
+	t.Helper()
+	f, err := os.Open(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	dump, err := traceback.Parse(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dump
+}
+
+func TestParse(t *testing.T) {
+
+	dump := parseFile(t, "testdata/panic.txt")
+
+	if want := "runtime error: index out of range [3] with length 3 [recovered]\npanic: wrapped: index out of range"; dump.Panic != want {
+		t.Errorf("Panic = %q, want %q", dump.Panic, want)
+	}
+	if len(dump.Goroutines) != 3 {
+		t.Fatalf("parsed %d goroutines, want 3", len(dump.Goroutines))
+	}
+
+	want := traceback.Goroutine{
+		ID:    7,
+		State: "running",
+		Frames: []traceback.Frame{
+			{"main.(*Parser).next", "0xc000010018, {0xc00001c030, 0x3, 0x3}", "/home/dev/parser/parser.go", 42, 0x1d},
+			{"main.Parse", "...", "/home/dev/parser/parser.go", 17, 0},
+			{"main.worker[...]", "0xc000066060", "/home/dev/parser/main.go", 31, 0x8f},
+		},
+		CreatedBy: &traceback.Frame{Function: "main.main", File: "/home/dev/parser/main.go", Line: 12, Offset: 0x45},
+		Creator:   1,
+	}
+	if got := dump.Goroutines[0]; !reflect.DeepEqual(got, want) {
+		t.Errorf("goroutine 7 =\n%#v\nwant\n%#v", got, want)
+	}
+
+	g := dump.Goroutines[2]
+	if g.ID != 9 || g.State != "select, locked to thread" || !g.Elided || len(g.Frames) != 1 || g.Frames[0].Line != 435 {
+		t.Errorf("goroutine 9 = %#v", g)
+	}
+	if g.CreatedBy == nil || g.CreatedBy.Function != "net/http.(*Server).Serve" || g.Creator != 0 {
+		t.Errorf("goroutine 9 created by %#v in %d", g.CreatedBy, g.Creator)
+	}
+	if f := g.CreatedBy; f.Package() != "net/http" || f.Name() != "(*Server).Serve" {
+		t.Errorf("Package, Name = %s, %s", f.Package(), f.Name())
+	}
+}
+
+func TestParseNoGoroutine(t *testing.T) {
+	if _, err := traceback.Parse(strings.NewReader("just some output\n")); err != traceback.ErrNoGoroutine {
+		t.Errorf("Parse = %v, want ErrNoGoroutine", err)
+	}
+}
+
+func TestFormat(t *testing.T) {
+
+	text, err := os.ReadFile("testdata/panic.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dump := parseFile(t, "testdata/panic.txt")
+
+	// %+v renders goroutines the way the runtime prints them
+	for _, g := range dump.Goroutines[:2] {
+		if s := fmt.Sprintf("%+v", g); !bytes.Contains(text, []byte(s)) {
+			t.Errorf("%%+v =\n%s\nnot contained in testdata/panic.txt", s)
+		}
+	}
+
+	g := dump.Goroutines[0]
+	if s := fmt.Sprint(g); s != "goroutine 7 [running]" {
+		t.Errorf("%%v = %s", s)
+	}
+	f := g.Frames[0]
+	for format, want := range map[string]string{
+		"%s":  "parser.go",
+		"%d":  "42",
+		"%n":  "(*Parser).next",
+		"%v":  "parser.go:42",
+		"%+v": "main.(*Parser).next\n\t/home/dev/parser/parser.go:42",
+	} {
+		if got := fmt.Sprintf(format, f); got != want {
+			t.Errorf("Sprintf(%q) = %q, want %q", format, got, want)
+		}
+	}
+}
+
+func TestFingerprint(t *testing.T) {
+
+	dump := parseFile(t, "testdata/panic.txt")
+	g := dump.Goroutines[0]
+
+	// arguments, lines and offsets do not matter
+	moved := g.Filter(func(traceback.Frame) bool { return true })
+	for i := range moved.Frames {
+		moved.Frames[i].Line += 10
+		moved.Frames[i].Args = ""
+	}
+	if g.Fingerprint() != moved.Fingerprint() {
+		t.Errorf("fingerprint changed with lines and arguments")
+	}
+
+	main := g.Filter(func(f traceback.Frame) bool { return f.Name() != "Parse" })
+	if len(main.Frames) != 2 || len(g.Frames) != 3 {
+		t.Errorf("Filter kept %d of %d frames, want 2 of 3", len(main.Frames), len(g.Frames))
+	}
+	if g.Fingerprint() == main.Fingerprint() {
+		t.Errorf("fingerprint did not change with the frames")
+	}
+}
+
+func TestParseRuntime(t *testing.T) {
+
+	dump, err := traceback.Parse(bytes.NewReader(debug.Stack()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fn := dump.Goroutines[0].Frames[1].Function; fn != "github.com/ihleven/errors/traceback_test.TestParseRuntime" {
+		t.Errorf("debug.Stack frame 1 = %s, want TestParseRuntime", fn)
+	}
+
+	buf := make([]byte, 1<<16)
+	dump, err = traceback.Parse(bytes.NewReader(buf[:runtime.Stack(buf, true)]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dump.Goroutines) < 2 || dump.Goroutines[0].State != "running" {
+		t.Errorf("runtime.Stack(all) parsed into %d goroutines, first %v", len(dump.Goroutines), dump.Goroutines[0])
+	}
+}
+
+func TestParsePanic(t *testing.T) {
+
+	if os.Getenv("TRACEBACK_PANIC") == "1" {
+		done := make(chan bool)
+		go func() {
+			var s []int
+			_ = s[3]
+			done <- true
+		}()
+		<-done
+		return
+	}
+
+	cmd := exec.Command(os.Args[0], "-test.run=^TestParsePanic$")
+	cmd.Env = append(os.Environ(), "TRACEBACK_PANIC=1")
+	out, _ := cmd.CombinedOutput()
+
+	dump, err := traceback.Parse(bytes.NewReader(out))
+	if err != nil {
+		t.Fatalf("%v parsing\n%s", err, out)
+	}
+	if !strings.HasPrefix(dump.Panic, "runtime error: index out of range [3] with length 0") {
+		t.Errorf("Panic = %q", dump.Panic)
+	}
+	g := dump.Goroutines[0]
+	if g.CreatedBy == nil || !strings.HasSuffix(g.CreatedBy.Function, "TestParsePanic") {
+		t.Errorf("goroutine created by %#v, want TestParsePanic", g.CreatedBy)
+	}
+	if f := g.Frames[0]; !strings.HasSuffix(f.Function, "TestParsePanic.func1") || !strings.HasSuffix(f.File, "traceback_test.go") {
+		t.Errorf("innermost frame %+v, want TestParsePanic.func1", f)
+	}
+}
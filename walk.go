@@ -0,0 +1,105 @@
+package errors
+
+import (
+	"reflect"
+)
+
+// Walk calls fn for err and for every error reachable from it, starting with err itself.
+//
+// An error leads to further errors if it implements one of
+//
+//	Cause() error
+//	Unwrap() error
+//	Unwrap() []error
+//
+// so both chains built by this package and chains built by fmt.Errorf("%w"),
+// errors.Join or other libraries are traversed. Errors wrapping multiple errors
+// are walked depth-first, branch by branch in order. Each error usable as a map key
+// is visited at most once, which also protects against cyclic chains. Walk stops as
+// soon as fn returns false.
+func Walk(err error, fn func(error) bool) {
+
+	if err == nil {
+		return
+	}
+
+	seen := make(map[error]bool)
+	todo := []error{err}
+
+	for len(todo) > 0 {
+		err, todo = todo[len(todo)-1], todo[:len(todo)-1]
+
+		if hashable(err) {
+			if seen[err] {
+				continue
+			}
+			seen[err] = true
+		}
+
+		if !fn(err) {
+			return
+		}
+
+		next := causes(err)
+		for i := len(next) - 1; i >= 0; i-- {
+			todo = append(todo, next[i])
+		}
+	}
+}
+
+// Chain returns err followed by every error reachable from it, in the order Walk visits them.
+func Chain(err error) []error {
+
+	var chain []error
+	Walk(err, func(e error) bool {
+		chain = append(chain, e)
+		return true
+	})
+	return chain
+}
+
+// Causer is implemented by errors wrapping a cause, like the errors returned by Wrap.
+// Cause returns the wrapped error, or nil if there is none. It is the interface of
+// github.com/pkg/errors and is followed by Cause and Walk alongside Unwrap.
+type Causer interface {
+	Cause() error
+}
+
+// causes returns the errors directly wrapped by err, Unwrap before Cause.
+func causes(err error) []error {
+
+	var errs []error
+
+	switch x := err.(type) {
+	case interface{ Unwrap() []error }:
+		for _, e := range x.Unwrap() {
+			if e != nil {
+				errs = append(errs, e)
+			}
+		}
+	case interface{ Unwrap() error }:
+		if e := x.Unwrap(); e != nil {
+			errs = append(errs, e)
+		}
+	}
+
+	if x, ok := err.(Causer); ok {
+		if e := x.Cause(); e != nil && (len(errs) != 1 || !same(e, errs[0])) {
+			errs = append(errs, e)
+		}
+	}
+
+	return errs
+}
+
+// same reports whether a and b are the identical error value.
+func same(a, b error) bool {
+	return reflect.TypeOf(a) == reflect.TypeOf(b) && hashable(a) && hashable(b) && a == b
+}
+
+// hashable reports whether err can be compared with == and used as a map key.
+// A comparable type is not sufficient: comparing a struct holding an incomparable
+// value like a slice in an interface field panics.
+func hashable(err error) bool {
+	return reflect.ValueOf(err).Comparable()
+}